@@ -0,0 +1,205 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package main converts a Terraform JSON plan to the input of the SCC IaC
+// validation and submits it to the validation API, or resolves mocked reports
+// against it or evaluates it against a posture to run the pipeline offline.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+	"github.com/mikelaramie/IACPlugin2024/posture"
+	"github.com/mikelaramie/IACPlugin2024/scc"
+	"github.com/mikelaramie/IACPlugin2024/tfplan"
+)
+
+var (
+	planFilePath     = flag.String("plan", "", "path of the terraform json plan, the output of terraform show -json")
+	outputFilePath   = flag.String("output", "assets.json", "path of the output file")
+	format           = flag.String("format", "assets", "output format: assets, the planned resources as Cloud Asset Inventory assets, or request, the body of the validation request")
+	project          = flag.String("project", "", "project of the planned resources not setting one")
+	mockReportPath   = flag.String("mockReport", "", "path of a mocked report whose violations reference planned resources by terraform address or asset name")
+	posturePath      = flag.String("posture", "", "path of a posture yaml file whose org policies the plan is evaluated against offline")
+	parent           = flag.String("parent", "", "organizations/<id>/locations/<location> to submit the plan to the IaC validation API under, authenticating with the access token in $"+ACCESS_TOKEN_ENV)
+	endpoint         = flag.String("endpoint", scc.DEFAULT_ENDPOINT, "endpoint of the IaC validation API")
+	timeout          = flag.Duration("timeout", 10*time.Minute, "maximum time to wait for the validation report of -parent")
+	reportOutputPath = flag.String("reportOutput", "report.json", "path of the report resolved from -mockReport, evaluated against -posture or returned by the API for -parent, in the format of gcloud scc iac-validation-reports create")
+)
+
+// ACCESS_TOKEN_ENV is the environment variable holding the OAuth access token
+// of the IaC validation API, as for the Terraform Google provider.
+const ACCESS_TOKEN_ENV = "GOOGLE_OAUTH_ACCESS_TOKEN"
+
+func main() {
+	flag.Parse()
+
+	reportSources := 0
+	for _, source := range []string{*mockReportPath, *posturePath, *parent} {
+		if source != "" {
+			reportSources++
+		}
+	}
+	if reportSources > 1 {
+		fmt.Printf("only one of -mockReport, -posture and -parent can be set")
+		os.Exit(1)
+	}
+
+	data, err := os.ReadFile(*planFilePath)
+	if err != nil {
+		fmt.Printf("os.ReadFile(%s): %v", *planFilePath, err)
+		os.Exit(1)
+	}
+
+	plan, err := tfplan.Parse(data)
+	if err != nil {
+		fmt.Printf("tfplan.Parse(): %v", err)
+		os.Exit(1)
+	}
+	assets := plan.Assets(*project)
+
+	var output interface{}
+	switch *format {
+	case "assets":
+		output = assets
+	case "request":
+		output = tfplan.NewValidationRequest(data)
+	default:
+		fmt.Printf("unsupported output format: %v", *format)
+		os.Exit(1)
+	}
+
+	// The assets or request are only written next to a report when -output
+	// asks for them, so that runs producing a report leave no stray file.
+	if reportSources == 0 || isFlagSet("output") {
+		if err := writeJSON(output, *outputFilePath); err != nil {
+			fmt.Printf("writeJSON(): %v", err)
+			os.Exit(1)
+		}
+	}
+
+	if *parent != "" {
+		operation, err := submit(data)
+		if err != nil {
+			fmt.Printf("submit(): %v", err)
+			os.Exit(1)
+		}
+
+		if err := writeJSON(operation, *reportOutputPath); err != nil {
+			fmt.Printf("writeJSON(): %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var report template.Responses
+	switch {
+	case *mockReportPath != "":
+		report, err = resolveMockReport(*mockReportPath, assets)
+		if err != nil {
+			fmt.Printf("resolveMockReport(): %v", err)
+			os.Exit(1)
+		}
+	case *posturePath != "":
+		p, err := posture.Load(*posturePath)
+		if err != nil {
+			fmt.Printf("posture.Load(): %v", err)
+			os.Exit(1)
+		}
+
+		report.IacValidationReport, err = posture.Evaluate(p, assets)
+		if err != nil {
+			fmt.Printf("posture.Evaluate(): %v", err)
+			os.Exit(1)
+		}
+	default:
+		return
+	}
+
+	operation := template.Operation{Name: "operations/local", Done: true, Response: report}
+	if err := writeJSON(operation, *reportOutputPath); err != nil {
+		fmt.Printf("writeJSON(): %v", err)
+		os.Exit(1)
+	}
+}
+
+// submit validates the plan with the IaC validation API and returns the done
+// operation.
+func submit(plan []byte) (template.Operation, error) {
+	token := os.Getenv(ACCESS_TOKEN_ENV)
+	if token == "" {
+		return template.Operation{}, fmt.Errorf("$%s is not set, set it to an access token, e.g. gcloud auth print-access-token", ACCESS_TOKEN_ENV)
+	}
+
+	client := scc.NewClient(&http.Client{Transport: scc.TokenTransport{Token: token}})
+	client.Endpoint = *endpoint
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	return client.CreateIaCValidationReport(ctx, *parent, plan)
+}
+
+func resolveMockReport(filePath string, assets []tfplan.Asset) (template.Responses, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return template.Responses{}, fmt.Errorf("os.ReadFile(%s): %v", filePath, err)
+	}
+
+	report, err := template.ParseReport(data)
+	if err != nil {
+		return template.Responses{}, fmt.Errorf("template.ParseReport(): %v", err)
+	}
+
+	report.IacValidationReport, err = tfplan.ResolveReport(report.IacValidationReport, assets)
+	if err != nil {
+		return template.Responses{}, fmt.Errorf("tfplan.ResolveReport(): %v", err)
+	}
+
+	return report, nil
+}
+
+// isFlagSet reports whether the flag was passed on the command line.
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
+
+func writeJSON(v interface{}, outputFilePath string) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent: %v", err)
+	}
+
+	if err := os.WriteFile(outputFilePath, data, 0o644); err != nil {
+		return fmt.Errorf("os.WriteFile: %v", err)
+	}
+
+	return nil
+}
@@ -18,66 +18,25 @@ package evaluate
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/mikelaramie/IACPlugin2024/ReportValidator/fileoperator"
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
 )
 
-func IsIACScanReportViolatingSeverity(filePath, expression *string) (bool, error) {
-	severityCounts, err := fileoperator.FetchViolationFromInputFile(filePath)
+// IsIACScanReportViolatingSeverity reports whether the violations of the
+// report file breach the expression, see ExplainViolations.
+func IsIACScanReportViolatingSeverity(filePath, expression *string, policy template.UnknownSeverityPolicy) (bool, error) {
+	violations, err := fileoperator.FetchViolationDetailsFromInputFile(filePath, policy)
 	if err != nil {
-		return false, fmt.Errorf("fetchViolationFromInputFile hi failed :%v", err)
+		return false, fmt.Errorf("fileoperator.FetchViolationDetailsFromInputFile: %v", err)
 	}
 
-	operator, userViolationCount, err := fileoperator.ProcessExpression(*expression)
+	trace, err := ExplainViolations(*expression, violations, Context{})
 	if err != nil {
-		return false, fmt.Errorf("processExpression failed :%v", err)
+		return false, fmt.Errorf("ExplainViolations: %v", err)
 	}
 
-	failureCriteriaViolations, err := computeViolationState(severityCounts, userViolationCount)
-	if err != nil {
-		return false, fmt.Errorf("computeViolationState failed :%v", err)
-	}
-
-	return isViolatingSeverity(operator, failureCriteriaViolations)
-}
-
-func computeViolationState(severityCounts map[string]int, userViolationCount map[string]int) (map[string]bool, error) {
-	failureCriteriaViolations := make(map[string]bool)
-
-	for k, violationLimit := range userViolationCount {
-		severity := strings.ToUpper(k)
-		switch severity {
-		case "CRITICAL":
-			if severityCounts["CRITICAL"] >= violationLimit {
-				failureCriteriaViolations["CRITICAL"] = true
-			} else {
-				failureCriteriaViolations["CRITICAL"] = false
-			}
-		case "HIGH":
-			if severityCounts["HIGH"] >= violationLimit {
-				failureCriteriaViolations["HIGH"] = true
-			} else {
-				failureCriteriaViolations["HIGH"] = false
-			}
-		case "MEDIUM":
-			if severityCounts["MEDIUM"] >= violationLimit {
-				failureCriteriaViolations["MEDIUM"] = true
-			} else {
-				failureCriteriaViolations["MEDIUM"] = false
-			}
-		case "LOW":
-			if severityCounts["LOW"] >= violationLimit {
-				failureCriteriaViolations["LOW"] = true
-			} else {
-				failureCriteriaViolations["LOW"] = false
-			}
-		default:
-			return nil, fmt.Errorf("invalid severity expression: %v", severity)
-		}
-	}
-
-	return failureCriteriaViolations, nil
+	return trace.Violated, nil
 }
 
 func isViolatingSeverity(operator string, failureCriteriaViolations map[string]bool) (bool, error) {
@@ -17,89 +17,43 @@
 package evaluate
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
-	"github.com/google/go-cmp/cmp"
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
 )
 
-func TestComputeViolationState(t *testing.T) {
+func TestIsIACScanReportViolatingSeverity(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "report.json")
+	report := `{"violations": [{"assetId": "a1", "policyId": "p1", "severity": "CRITICAL"}, {"assetId": "a2", "policyId": "p2", "severity": "LOW"}]}`
+	if err := os.WriteFile(filePath, []byte(report), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
 	tests := []struct {
-		name                              string
-		severityCounts                    map[string]int
-		userViolationCount                map[string]int
-		expectedFailureCriteriaViolations map[string]bool
-		wantErr                           bool
+		name             string
+		expression       string
+		expectedViolated bool
+		wantErr          bool
 	}{
-		{
-			name: "CriticalAndHighLevelSeverityExcceeded",
-			severityCounts: map[string]int{
-				"CRITICAL": 2,
-				"HIGH":     2,
-				"MEDIUM":   0,
-				"LOW":      0,
-			},
-			userViolationCount: map[string]int{
-				"CRITICAL": 1,
-				"HIGH":     1,
-				"MEDIUM":   0,
-			},
-			expectedFailureCriteriaViolations: map[string]bool{
-				"CRITICAL": true,
-				"HIGH":     true,
-				"MEDIUM":   false,
-			},
-			wantErr: false,
-		},
-		{
-			name: "MediumAndLowLevelSeverityExcceeded",
-			severityCounts: map[string]int{
-				"CRITICAL": 0,
-				"MEDIUM":   3,
-				"LOW":      2,
-			},
-			userViolationCount: map[string]int{
-				"CRITICAL": 0,
-				"HIGH":     0,
-				"MEDIUM":   1,
-				"LOW":      1,
-			},
-			expectedFailureCriteriaViolations: map[string]bool{
-				"LOW":      true,
-				"MEDIUM":   true,
-				"HIGH":     false,
-				"CRITICAL": false,
-			},
-			wantErr: false,
-		},
-		{
-			name: "InvalidSeverity_Error",
-			severityCounts: map[string]int{
-				"CRITICAL": 2,
-				"HIGH":     1,
-				"MEDIUM":   0,
-			},
-			userViolationCount: map[string]int{
-				"CRITICAL": 1,
-				"HIGH":     2,
-				"MEDIUM":   1,
-				"invalid":  3,
-			},
-			expectedFailureCriteriaViolations: nil,
-			wantErr:                           true,
-		},
+		{name: "DefaultCriteria_Violated", expression: "", expectedViolated: true},
+		{name: "ORCriteria_Violated", expression: "critical>=1,high>=1,operator:or", expectedViolated: true},
+		{name: "ANDCriteria_NotViolated", expression: "critical>=1,high>=1,operator:and", expectedViolated: false},
+		{name: "ColonSyntaxReachingThreshold_Violated", expression: "critical:1,operator:or", expectedViolated: true},
+		{name: "EqualComparator_Violated", expression: "high==0,operator:or", expectedViolated: true},
+		{name: "InvalidExpression_Failure", expression: "critical>=1", wantErr: true},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			t.Parallel()
-
-			failureCriteriaViolations, err := computeViolationState(test.severityCounts, test.userViolationCount)
+			violated, err := IsIACScanReportViolatingSeverity(&filePath, &test.expression, template.UnknownSeverityPolicy{})
 			if (err != nil) != test.wantErr {
-				t.Errorf("Expected error: %v, got: %v", test.wantErr, err)
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
 			}
 
-			if diff := cmp.Diff(test.expectedFailureCriteriaViolations, failureCriteriaViolations); diff != "" {
-				t.Errorf("Expected failureCriteriaViolations (+got, -want): %v", diff)
+			if violated != test.expectedViolated {
+				t.Errorf("Unexpected verdict want: %v, got: %v", test.expectedViolated, violated)
 			}
 		})
 	}
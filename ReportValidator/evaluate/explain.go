@@ -0,0 +1,331 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package evaluate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mikelaramie/IACPlugin2024/ReportValidator/fileoperator"
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+// Trace records how the validation outcome was reached.
+type Trace struct {
+	Expression string
+	Operator   string
+	Criteria   []CriterionTrace
+	// DecidedBy is the severity whose criterion short-circuited the operator,
+	// empty when every criterion had to be evaluated.
+	DecidedBy string
+	Violated  bool
+	// Score is the risk score of the violations, set when the expression has
+	// a score criterion.
+	Score *template.Score
+	// Warnings explain how to migrate the criteria of the expression using
+	// the deprecated colon syntax.
+	Warnings []string
+}
+
+// CriterionTrace is the evaluation of one failure criterion.
+type CriterionTrace struct {
+	Severity   string
+	Comparator string
+	Threshold  int
+	Count      int
+	// Ratio criteria compare Percent, the violations per hundred of the
+	// Assets scanned of AssetType when set, with ThresholdPercent.
+	Ratio            bool
+	AssetType        string
+	Assets           int
+	Percent          float64
+	ThresholdPercent float64
+	// Score criteria compare the risk score of the violations, ScoreValue,
+	// with ThresholdScore.
+	Score          bool
+	ScoreValue     float64
+	ThresholdScore float64
+	Breached       bool
+	Violations     []template.Violation
+}
+
+// AssetInventory is the number of assets scanned, in total and per asset
+// type, that ratio criteria are relative to.
+type AssetInventory struct {
+	Total  int
+	ByType map[string]int
+}
+
+// Context holds what criteria other than counts are evaluated against.
+type Context struct {
+	// Assets are the assets scanned, for the ratio criteria.
+	Assets AssetInventory
+	// Scoped reports that the violations were scoped by a filter or an
+	// owner. The assets are not, so ratio criteria are rejected rather than
+	// diluted by the assets out of the scope.
+	Scoped bool
+	// ScoreWeights weigh the violations for the score criteria, the zero
+	// value uses template.DefaultScoreWeights.
+	ScoreWeights template.ScoreWeights
+}
+
+// ExplainViolations evaluates violations already loaded in memory against the
+// expression, within the context of the run, and returns the trace of the
+// evaluation. Severities are expected in upper case, see
+// template.ApplyUnknownSeverityPolicy.
+func ExplainViolations(expression string, violations []template.Violation, ctx Context) (Trace, error) {
+	operator, criteria, err := fileoperator.ParseCriteria(expression)
+	if err != nil {
+		return Trace{}, fmt.Errorf("processExpression failed :%v", err)
+	}
+
+	return explainViolations(expression, operator, criteria, violations, ctx)
+}
+
+func explainViolations(expression, operator string, criteria []fileoperator.Criterion, violations []template.Violation, ctx Context) (Trace, error) {
+	severityCounts := make(map[string]int)
+	violationsBySeverity := make(map[string][]template.Violation)
+	for _, v := range violations {
+		severity := strings.ToUpper(v.Severity)
+		severityCounts[severity]++
+		violationsBySeverity[severity] = append(violationsBySeverity[severity], v)
+	}
+
+	var warnings []string
+	var score *template.Score
+	failureCriteriaViolations := make(map[string]bool)
+	criterionTraces := []CriterionTrace{}
+	for _, criterion := range criteria {
+		if warning := criterion.MigrationWarning(); warning != "" {
+			warnings = append(warnings, warning)
+		}
+
+		var criterionTrace CriterionTrace
+		switch {
+		case criterion.Score:
+			if score == nil {
+				s := template.ComputeScore(violations, ctx.ScoreWeights)
+				score = &s
+			}
+			criterionTrace = CriterionTrace{
+				Comparator:     criterion.Comparator,
+				Score:          true,
+				ScoreValue:     score.Total,
+				ThresholdScore: criterion.ThresholdScore,
+				Count:          len(violations),
+				Breached:       compare(score.Total, criterion.Comparator, criterion.ThresholdScore),
+			}
+		case criterion.Ratio:
+			if ctx.Scoped {
+				return Trace{}, fmt.Errorf("ratio criterion %v can not be evaluated on scoped violations, the assets scanned are not scoped", criterion)
+			}
+			var err error
+			criterionTrace, err = computeRatio(criterion, violationsBySeverity[criterion.Severity], ctx.Assets)
+			if err != nil {
+				return Trace{}, fmt.Errorf("computeRatio failed :%v", err)
+			}
+		default:
+			if !template.Severity(criterion.Severity).IsKnown() {
+				return Trace{}, fmt.Errorf("invalid severity expression: %v", criterion.Severity)
+			}
+			criterionTrace = CriterionTrace{
+				Severity:   criterion.Severity,
+				Comparator: criterion.Comparator,
+				Threshold:  criterion.Threshold,
+				Count:      severityCounts[criterion.Severity],
+				Breached:   compare(float64(severityCounts[criterion.Severity]), criterion.Comparator, float64(criterion.Threshold)),
+			}
+			if criterionTrace.Breached {
+				criterionTrace.Violations = violationsBySeverity[criterion.Severity]
+			}
+		}
+
+		failureCriteriaViolations[criterionTrace.Label()] = criterionTrace.Breached
+		criterionTraces = append(criterionTraces, criterionTrace)
+	}
+
+	isViolated, err := isViolatingSeverity(operator, failureCriteriaViolations)
+	if err != nil {
+		return Trace{}, fmt.Errorf("isViolatingSeverity failed :%v", err)
+	}
+
+	trace := Trace{
+		Expression: expression,
+		Operator:   operator,
+		Violated:   isViolated,
+		Score:      score,
+		Warnings:   warnings,
+	}
+
+	sort.SliceStable(criterionTraces, func(i, j int) bool {
+		a, b := criterionTraces[i], criterionTraces[j]
+		if rankA, rankB := template.Severity(a.Severity).Rank(), template.Severity(b.Severity).Rank(); rankA != rankB {
+			return rankA > rankB
+		}
+		if a.Score != b.Score {
+			return b.Score
+		}
+		return !a.Ratio && b.Ratio
+	})
+
+	for _, criterion := range criterionTraces {
+		trace.Criteria = append(trace.Criteria, criterion)
+
+		// OR is decided by the first breached criterion, AND by the first one
+		// that is not breached.
+		if trace.DecidedBy == "" && criterion.Breached == (operator == "OR") {
+			trace.DecidedBy = criterion.Label()
+		}
+	}
+
+	return trace, nil
+}
+
+// compare reports whether the value compares with the threshold.
+func compare(value float64, comparator string, threshold float64) bool {
+	switch comparator {
+	case fileoperator.COMPARATOR_GREATER:
+		return value > threshold
+	case fileoperator.COMPARATOR_GREATER_EQUAL:
+		return value >= threshold
+	case fileoperator.COMPARATOR_EQUAL:
+		return value == threshold
+	case fileoperator.COMPARATOR_LESS_EQUAL:
+		return value <= threshold
+	case fileoperator.COMPARATOR_LESS:
+		return value < threshold
+	default:
+		return false
+	}
+}
+
+// computeRatio evaluates a ratio criterion against the violations of its
+// severity.
+func computeRatio(criterion fileoperator.Criterion, violations []template.Violation, assets AssetInventory) (CriterionTrace, error) {
+	if !template.Severity(criterion.Severity).IsKnown() {
+		return CriterionTrace{}, fmt.Errorf("invalid severity expression: %v", criterion.Severity)
+	}
+
+	ratio := CriterionTrace{
+		Severity:         criterion.Severity,
+		Comparator:       criterion.Comparator,
+		Ratio:            true,
+		AssetType:        criterion.AssetType,
+		ThresholdPercent: criterion.ThresholdPercent,
+		Assets:           assets.Total,
+	}
+
+	if criterion.AssetType == "" {
+		if assets.Total == 0 {
+			return CriterionTrace{}, fmt.Errorf("ratio criteria require the number of assets scanned")
+		}
+		ratio.Violations = violations
+	} else {
+		if assets.ByType == nil {
+			return CriterionTrace{}, fmt.Errorf("ratio criteria on an asset type require the assets scanned per type")
+		}
+		ratio.Assets = assets.ByType[criterion.AssetType]
+		for _, v := range violations {
+			if v.ViolatedAsset.AssetType == criterion.AssetType {
+				ratio.Violations = append(ratio.Violations, v)
+			}
+		}
+		// Violations on assets missing from the inventory can not be
+		// weighed, which would otherwise never breach the criterion.
+		if len(ratio.Violations) > 0 && ratio.Assets == 0 {
+			return CriterionTrace{}, fmt.Errorf("no asset of type %s scanned for its %d violations", criterion.AssetType, len(ratio.Violations))
+		}
+	}
+
+	ratio.Count = len(ratio.Violations)
+	if ratio.Assets > 0 {
+		ratio.Percent = float64(ratio.Count) * 100 / float64(ratio.Assets)
+	}
+	ratio.Breached = compare(ratio.Percent, ratio.Comparator, ratio.ThresholdPercent)
+	if !ratio.Breached {
+		ratio.Violations = nil
+	}
+
+	return ratio, nil
+}
+
+// Label names the criterion, e.g. HIGH or HIGH/assets[storage.googleapis.com/Bucket].
+func (c CriterionTrace) Label() string {
+	if c.Score {
+		return "score"
+	}
+	if !c.Ratio {
+		return c.Severity
+	}
+	if c.AssetType != "" {
+		return fmt.Sprintf("%s/assets[%s]", c.Severity, c.AssetType)
+	}
+
+	return c.Severity + "/assets"
+}
+
+// String renders the trace for the console.
+func (t Trace) String() string {
+	var b strings.Builder
+
+	expression := t.Expression
+	if expression == "" {
+		expression = "<default>"
+	}
+	fmt.Fprintf(&b, "Expression: %s\n", expression)
+
+	terms := make([]string, 0, len(t.Criteria))
+	for _, c := range t.Criteria {
+		if c.Ratio {
+			terms = append(terms, fmt.Sprintf("%s %s %g%%", c.Label(), c.Comparator, c.ThresholdPercent))
+			continue
+		}
+		if c.Score {
+			terms = append(terms, fmt.Sprintf("%s %s %g", c.Label(), c.Comparator, c.ThresholdScore))
+			continue
+		}
+		terms = append(terms, fmt.Sprintf("%s %s %d", c.Severity, c.Comparator, c.Threshold))
+	}
+	fmt.Fprintf(&b, "Parsed expression: %s(%s)\n", t.Operator, strings.Join(terms, ", "))
+
+	fmt.Fprintf(&b, "Criteria:\n")
+	for _, c := range t.Criteria {
+		state := "not breached"
+		if c.Breached {
+			state = "breached"
+		}
+		if c.Ratio {
+			fmt.Fprintf(&b, "  %s: %d violations on %d assets = %.2f%% %s threshold %g%% -> %s\n", c.Label(), c.Count, c.Assets, c.Percent, c.Comparator, c.ThresholdPercent, state)
+		} else if c.Score {
+			fmt.Fprintf(&b, "  %s: %g %s threshold %g -> %s\n", c.Label(), c.ScoreValue, c.Comparator, c.ThresholdScore, state)
+		} else {
+			fmt.Fprintf(&b, "  %s: count %d %s threshold %d -> %s\n", c.Severity, c.Count, c.Comparator, c.Threshold, state)
+		}
+		for _, v := range c.Violations {
+			fmt.Fprintf(&b, "    - policy %s on asset %s\n", v.PolicyID, v.AssetID)
+		}
+	}
+
+	if t.DecidedBy != "" {
+		fmt.Fprintf(&b, "Operator %s short-circuited at %s: %v\n", t.Operator, t.DecidedBy, t.Violated)
+	} else {
+		fmt.Fprintf(&b, "Operator %s evaluated all criteria: %v\n", t.Operator, t.Violated)
+	}
+
+	return b.String()
+}
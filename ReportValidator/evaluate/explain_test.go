@@ -0,0 +1,320 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package evaluate
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/mikelaramie/IACPlugin2024/ReportValidator/fileoperator"
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+func TestExplainViolations(t *testing.T) {
+	violations := []template.Violation{
+		{AssetID: "asset1", PolicyID: "policy1", Severity: "CRITICAL"},
+		{AssetID: "asset2", PolicyID: "policy2", Severity: "high"},
+		{AssetID: "asset3", PolicyID: "policy1", Severity: "CRITICAL"},
+	}
+
+	tests := []struct {
+		name          string
+		operator      string
+		criteria      []fileoperator.Criterion
+		expectedTrace Trace
+		wantErr       bool
+	}{
+		{
+			name:     "OROperator_ShortCircuitsOnBreach",
+			operator: "OR",
+			criteria: []fileoperator.Criterion{{Severity: "LOW", Comparator: ">=", Threshold: 1}, {Severity: "HIGH", Comparator: ">=", Threshold: 2}, {Severity: "CRITICAL", Comparator: ">=", Threshold: 2}},
+			expectedTrace: Trace{
+				Operator: "OR",
+				Criteria: []CriterionTrace{
+					{
+						Severity: "CRITICAL", Comparator: ">=", Threshold: 2, Count: 2, Breached: true,
+						Violations: []template.Violation{violations[0], violations[2]},
+					},
+					{Severity: "HIGH", Comparator: ">=", Threshold: 2, Count: 1},
+					{Severity: "LOW", Comparator: ">=", Threshold: 1},
+				},
+				DecidedBy: "CRITICAL",
+				Violated:  true,
+			},
+		},
+		{
+			name:     "ANDOperator_ShortCircuitsOnPass",
+			operator: "AND",
+			criteria: []fileoperator.Criterion{{Severity: "HIGH", Comparator: ">=", Threshold: 1}, {Severity: "MEDIUM", Comparator: ">=", Threshold: 1}},
+			expectedTrace: Trace{
+				Operator: "AND",
+				Criteria: []CriterionTrace{
+					{
+						Severity: "HIGH", Comparator: ">=", Threshold: 1, Count: 1, Breached: true,
+						Violations: []template.Violation{violations[1]},
+					},
+					{Severity: "MEDIUM", Comparator: ">=", Threshold: 1},
+				},
+				DecidedBy: "MEDIUM",
+				Violated:  false,
+			},
+		},
+		{
+			name:     "ExplicitComparators_Succeeds",
+			operator: "OR",
+			criteria: []fileoperator.Criterion{{Severity: "CRITICAL", Comparator: ">", Threshold: 2}, {Severity: "LOW", Comparator: "==", Threshold: 0}},
+			expectedTrace: Trace{
+				Operator: "OR",
+				Criteria: []CriterionTrace{
+					{Severity: "CRITICAL", Comparator: ">", Threshold: 2, Count: 2},
+					{Severity: "LOW", Comparator: "==", Breached: true},
+				},
+				DecidedBy: "LOW",
+				Violated:  true,
+			},
+		},
+		{
+			name:     "InvalidSeverity_Failure",
+			operator: "OR",
+			criteria: []fileoperator.Criterion{{Severity: "URGENT", Comparator: ">=", Threshold: 1}},
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			trace, err := explainViolations("", test.operator, test.criteria, violations, Context{})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if diff := cmp.Diff(test.expectedTrace, trace); diff != "" {
+				t.Errorf("Expected trace (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestTraceString(t *testing.T) {
+	trace := Trace{
+		Expression: "critical>=1,high>=1,operator:or",
+		Operator:   "OR",
+		Criteria: []CriterionTrace{
+			{
+				Severity: "CRITICAL", Comparator: ">=", Threshold: 1, Count: 1, Breached: true,
+				Violations: []template.Violation{{AssetID: "asset1", PolicyID: "policy1"}},
+			},
+			{Severity: "HIGH", Comparator: ">=", Threshold: 1},
+		},
+		DecidedBy: "CRITICAL",
+		Violated:  true,
+	}
+
+	expected := `Expression: critical>=1,high>=1,operator:or
+Parsed expression: OR(CRITICAL >= 1, HIGH >= 1)
+Criteria:
+  CRITICAL: count 1 >= threshold 1 -> breached
+    - policy policy1 on asset asset1
+  HIGH: count 0 >= threshold 1 -> not breached
+Operator OR short-circuited at CRITICAL: true
+`
+
+	if diff := cmp.Diff(expected, trace.String()); diff != "" {
+		t.Errorf("Expected trace output (+got, -want): %v", diff)
+	}
+}
+
+func TestExplainViolations_Ratio(t *testing.T) {
+	violations := []template.Violation{
+		{AssetID: "bucket1", Severity: "HIGH", ViolatedAsset: template.AssetDetails{AssetType: "storage.googleapis.com/Bucket"}},
+		{AssetID: "vm1", Severity: "HIGH", ViolatedAsset: template.AssetDetails{AssetType: "compute.googleapis.com/Instance"}},
+		{AssetID: "vm2", Severity: "LOW", ViolatedAsset: template.AssetDetails{AssetType: "compute.googleapis.com/Instance"}},
+	}
+	assets := AssetInventory{
+		Total:  40,
+		ByType: map[string]int{"storage.googleapis.com/Bucket": 4, "compute.googleapis.com/Instance": 36},
+	}
+
+	tests := []struct {
+		name             string
+		expression       string
+		assets           AssetInventory
+		scoped           bool
+		expectedViolated bool
+		expectedDecided  string
+		wantErr          bool
+	}{
+		{
+			name:             "RatioBelowThreshold_NotViolated",
+			expression:       "high/assets>=10%,operator:or",
+			assets:           assets,
+			expectedViolated: false,
+		},
+		{
+			name:             "RatioAboveThreshold_Violated",
+			expression:       "high/assets>=5%,operator:or",
+			assets:           assets,
+			expectedViolated: true,
+			expectedDecided:  "HIGH/assets",
+		},
+		{
+			name:             "AssetTypeRatio_Violated",
+			expression:       "critical>=1,high/assets[storage.googleapis.com/Bucket]>=25%,operator:or",
+			assets:           assets,
+			expectedViolated: true,
+			expectedDecided:  "HIGH/assets[storage.googleapis.com/Bucket]",
+		},
+		{
+			name:       "ScopedRatio_Failure",
+			expression: "high/assets>=5%,operator:or",
+			assets:     assets,
+			scoped:     true,
+			wantErr:    true,
+		},
+		{
+			name:             "ScopedCount_Violated",
+			expression:       "high>=1,operator:or",
+			scoped:           true,
+			expectedViolated: true,
+			expectedDecided:  "HIGH",
+		},
+		{
+			name:       "RatioWithoutAssets_Failure",
+			expression: "high/assets>=5%,operator:or",
+			wantErr:    true,
+		},
+		{
+			name:       "UnscannedTypeWithoutViolations_NotViolated",
+			expression: "high/assets[sqladmin.googleapis.com/Instance]>=5%,operator:or",
+			assets:     assets,
+		},
+		{
+			name:       "AssetTypeRatioOfUnscannedType_Failure",
+			expression: "low/assets[compute.googleapis.com/Instance]>=5%,operator:or",
+			assets:     AssetInventory{Total: 40, ByType: map[string]int{"storage.googleapis.com/Bucket": 4}},
+			wantErr:    true,
+		},
+		{
+			name:       "AssetTypeRatioWithoutTypes_Failure",
+			expression: "high/assets[storage.googleapis.com/Bucket]>=5%,operator:or",
+			assets:     AssetInventory{Total: 40},
+			wantErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			trace, err := ExplainViolations(test.expression, violations, Context{Assets: test.assets, Scoped: test.scoped})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if trace.Violated != test.expectedViolated || trace.DecidedBy != test.expectedDecided {
+				t.Errorf("Expected violated: %v decided by %q, got: %v decided by %q", test.expectedViolated, test.expectedDecided, trace.Violated, trace.DecidedBy)
+			}
+		})
+	}
+}
+
+func TestTraceString_Ratio(t *testing.T) {
+	trace := Trace{
+		Expression: "high/assets[storage.googleapis.com/Bucket]>=25%,operator:or",
+		Operator:   "OR",
+		Criteria: []CriterionTrace{
+			{
+				Severity: "HIGH", Comparator: ">=", Ratio: true, AssetType: "storage.googleapis.com/Bucket",
+				Count: 1, Assets: 4, Percent: 25, ThresholdPercent: 25, Breached: true,
+				Violations: []template.Violation{{AssetID: "bucket1", PolicyID: "policy1"}},
+			},
+		},
+		DecidedBy: "HIGH/assets[storage.googleapis.com/Bucket]",
+		Violated:  true,
+	}
+
+	expected := `Expression: high/assets[storage.googleapis.com/Bucket]>=25%,operator:or
+Parsed expression: OR(HIGH/assets[storage.googleapis.com/Bucket] >= 25%)
+Criteria:
+  HIGH/assets[storage.googleapis.com/Bucket]: 1 violations on 4 assets = 25.00% >= threshold 25% -> breached
+    - policy policy1 on asset bucket1
+Operator OR short-circuited at HIGH/assets[storage.googleapis.com/Bucket]: true
+`
+
+	if diff := cmp.Diff(expected, trace.String()); diff != "" {
+		t.Errorf("Expected trace output (+got, -want): %v", diff)
+	}
+}
+
+func TestExplainViolations_Score(t *testing.T) {
+	violations := []template.Violation{
+		{AssetID: "bucket1", PolicyID: "policy1", Severity: "HIGH", ViolatedAsset: template.AssetDetails{AssetType: "storage.googleapis.com/Bucket"}},
+		{AssetID: "vm1", PolicyID: "policy2", Severity: "MEDIUM", ViolatedAsset: template.AssetDetails{AssetType: "compute.googleapis.com/Instance"}},
+		{AssetID: "vm2", PolicyID: "policy2", Severity: "LOW", ViolatedAsset: template.AssetDetails{AssetType: "compute.googleapis.com/Instance"}},
+	}
+
+	tests := []struct {
+		name             string
+		expression       string
+		weights          template.ScoreWeights
+		expectedViolated bool
+		expectedDecided  string
+		expectedTotal    float64
+	}{
+		{
+			name:             "DefaultWeightsBelowThreshold_NotViolated",
+			expression:       "score>=10,operator:or",
+			expectedViolated: false,
+			expectedTotal:    8,
+		},
+		{
+			name:             "DefaultWeightsAboveThreshold_Violated",
+			expression:       "score>=8,operator:or",
+			expectedViolated: true,
+			expectedDecided:  "score",
+			expectedTotal:    8,
+		},
+		{
+			name:       "PolicyFactor_Violated",
+			expression: "score>=10,critical>=1,operator:or",
+			weights: template.ScoreWeights{
+				Severities: template.DefaultScoreWeights.Severities,
+				Policies:   map[string]float64{"policy2": 3},
+			},
+			expectedViolated: true,
+			expectedDecided:  "score",
+			expectedTotal:    14,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			trace, err := ExplainViolations(test.expression, violations, Context{ScoreWeights: test.weights})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if trace.Violated != test.expectedViolated || trace.DecidedBy != test.expectedDecided {
+				t.Errorf("Expected violated: %v decided by %q, got: %v decided by %q", test.expectedViolated, test.expectedDecided, trace.Violated, trace.DecidedBy)
+			}
+			if trace.Score == nil || trace.Score.Total != test.expectedTotal {
+				t.Errorf("Expected score: %v, got: %+v", test.expectedTotal, trace.Score)
+			}
+		})
+	}
+}
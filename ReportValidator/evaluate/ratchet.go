@@ -0,0 +1,94 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package evaluate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+// RatchetResult is the comparison of the severity counts of a run against the
+// stored baseline.
+type RatchetResult struct {
+	// Increased lists the severities whose count grew, failing the run.
+	Increased []SeverityDelta
+	// Decreased lists the severities whose count dropped, tightening the
+	// baseline when none grew.
+	Decreased []SeverityDelta
+	Violated  bool
+}
+
+// SeverityDelta is the change of the count of a severity against the
+// baseline.
+type SeverityDelta struct {
+	Severity string
+	Baseline int
+	Count    int
+}
+
+// Ratchet compares the severity counts against the baseline: the run fails
+// when any count grows, whatever the absolute counts.
+func Ratchet(baseline, counts map[string]int) RatchetResult {
+	result := RatchetResult{}
+
+	for _, severity := range template.KnownSeverities {
+		delta := SeverityDelta{
+			Severity: string(severity),
+			Baseline: baseline[string(severity)],
+			Count:    counts[string(severity)],
+		}
+		switch {
+		case delta.Count > delta.Baseline:
+			result.Increased = append(result.Increased, delta)
+		case delta.Count < delta.Baseline:
+			result.Decreased = append(result.Decreased, delta)
+		}
+	}
+	result.Violated = len(result.Increased) > 0
+
+	return result
+}
+
+// ShouldTighten reports whether the baseline should be replaced by the
+// counts of the run.
+func (r RatchetResult) ShouldTighten() bool {
+	return !r.Violated && len(r.Decreased) > 0
+}
+
+// String renders the comparison for the console.
+func (r RatchetResult) String() string {
+	var b strings.Builder
+
+	for _, delta := range r.Increased {
+		fmt.Fprintf(&b, "%s: count %d > baseline %d -> increased\n", delta.Severity, delta.Count, delta.Baseline)
+	}
+	for _, delta := range r.Decreased {
+		fmt.Fprintf(&b, "%s: count %d < baseline %d -> decreased\n", delta.Severity, delta.Count, delta.Baseline)
+	}
+	switch {
+	case r.Violated:
+		b.WriteString("Ratchet: violation counts increased\n")
+	case r.ShouldTighten():
+		b.WriteString("Ratchet: violation counts decreased, tightening the baseline\n")
+	default:
+		b.WriteString("Ratchet: violation counts unchanged\n")
+	}
+
+	return b.String()
+}
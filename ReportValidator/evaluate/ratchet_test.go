@@ -0,0 +1,72 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package evaluate
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRatchet(t *testing.T) {
+	tests := []struct {
+		name            string
+		baseline        map[string]int
+		counts          map[string]int
+		expectedResult  RatchetResult
+		expectedTighten bool
+	}{
+		{
+			name:           "Unchanged_NotViolated",
+			baseline:       map[string]int{"HIGH": 2},
+			counts:         map[string]int{"HIGH": 2},
+			expectedResult: RatchetResult{},
+		},
+		{
+			name:     "Decreased_Tightened",
+			baseline: map[string]int{"HIGH": 2, "LOW": 5},
+			counts:   map[string]int{"HIGH": 1, "LOW": 5},
+			expectedResult: RatchetResult{
+				Decreased: []SeverityDelta{{Severity: "HIGH", Baseline: 2, Count: 1}},
+			},
+			expectedTighten: true,
+		},
+		{
+			name:     "IncreasedDespiteDecrease_Violated",
+			baseline: map[string]int{"HIGH": 2, "LOW": 5},
+			counts:   map[string]int{"CRITICAL": 1, "LOW": 3},
+			expectedResult: RatchetResult{
+				Increased: []SeverityDelta{{Severity: "CRITICAL", Baseline: 0, Count: 1}},
+				Decreased: []SeverityDelta{{Severity: "HIGH", Baseline: 2, Count: 0}, {Severity: "LOW", Baseline: 5, Count: 3}},
+				Violated:  true,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := Ratchet(test.baseline, test.counts)
+
+			if diff := cmp.Diff(test.expectedResult, result); diff != "" {
+				t.Errorf("Expected ratchet result (+got, -want): %v", diff)
+			}
+			if result.ShouldTighten() != test.expectedTighten {
+				t.Errorf("Expected tighten: %v, got: %v", test.expectedTighten, result.ShouldTighten())
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fileoperator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RATIO_DENOMINATOR is the denominator of the ratio criteria, e.g.
+// high/assets>=5% or high/assets[storage.googleapis.com/Bucket]>=10%.
+const RATIO_DENOMINATOR = "ASSETS"
+
+// SCORE_KEY is the key of the risk score criterion, e.g. score>=25.
+const SCORE_KEY = "SCORE"
+
+// Comparators of the criteria, a criterion being breached when its value
+// compares with the threshold. The deprecated colon syntax, e.g. critical:2,
+// keeps its former meaning and stands for COMPARATOR_GREATER_EQUAL.
+const (
+	COMPARATOR_GREATER       = ">"
+	COMPARATOR_GREATER_EQUAL = ">="
+	COMPARATOR_EQUAL         = "=="
+	COMPARATOR_LESS_EQUAL    = "<="
+	COMPARATOR_LESS          = "<"
+)
+
+// comparators are matched in order, the two-character ones first.
+var comparators = []string{COMPARATOR_GREATER_EQUAL, COMPARATOR_LESS_EQUAL, COMPARATOR_EQUAL, COMPARATOR_GREATER, COMPARATOR_LESS}
+
+// Criterion is one failure criterion of an expression.
+type Criterion struct {
+	Severity   string
+	Comparator string
+	// Threshold is the violation count a count criterion compares with.
+	Threshold int
+	// Ratio criteria compare the number of violations of the severity per
+	// hundred assets scanned, of AssetType when set, with ThresholdPercent.
+	Ratio            bool
+	AssetType        string
+	ThresholdPercent float64
+	// Score criteria compare the weighted risk score of the violations with
+	// ThresholdScore.
+	Score          bool
+	ThresholdScore float64
+	// Deprecated is the term as written when it uses the colon syntax.
+	Deprecated string
+}
+
+// DefaultCriteria fail on any violation.
+func DefaultCriteria() []Criterion {
+	criteria := []Criterion{}
+	for _, severity := range []string{"CRITICAL", "HIGH", "MEDIUM", "LOW"} {
+		criteria = append(criteria, Criterion{Severity: severity, Comparator: COMPARATOR_GREATER_EQUAL, Threshold: 1})
+	}
+
+	return criteria
+}
+
+// String renders the criterion in the explicit syntax, e.g. critical>2,
+// high/assets[storage.googleapis.com/Bucket]>=5% or score>=25.
+func (c Criterion) String() string {
+	return c.render(c.Comparator)
+}
+
+// MigrationWarning explains how to rewrite a criterion using the deprecated
+// colon syntax, and is empty for the other criteria.
+func (c Criterion) MigrationWarning() string {
+	if c.Deprecated == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%q uses the deprecated colon syntax, which fails when the threshold is reached: write %q instead",
+		c.Deprecated, c.render(COMPARATOR_GREATER_EQUAL))
+}
+
+func (c Criterion) render(comparator string) string {
+	switch {
+	case c.Score:
+		return fmt.Sprintf("%s%s%g", strings.ToLower(SCORE_KEY), comparator, c.ThresholdScore)
+	case c.Ratio && c.AssetType != "":
+		return fmt.Sprintf("%s/%s[%s]%s%g%%", strings.ToLower(c.Severity), strings.ToLower(RATIO_DENOMINATOR), c.AssetType, comparator, c.ThresholdPercent)
+	case c.Ratio:
+		return fmt.Sprintf("%s/%s%s%g%%", strings.ToLower(c.Severity), strings.ToLower(RATIO_DENOMINATOR), comparator, c.ThresholdPercent)
+	default:
+		return fmt.Sprintf("%s%s%d", strings.ToLower(c.Severity), comparator, c.Threshold)
+	}
+}
+
+// ParseCriteria parses the expression into its operator and criteria, in the
+// order of the expression. Each criterion is a key, a comparator and a value,
+// e.g. critical>2, high>=1 or low==0; the deprecated key:value syntax stands
+// for key>=value, as in earlier versions. The default criteria apply to an empty expression.
+func ParseCriteria(expression string) (string, []Criterion, error) {
+	if expression == "" {
+		return "OR", DefaultCriteria(), nil
+	}
+
+	var operator = ""
+	criteria := []Criterion{}
+	keys := make(map[string]bool)
+
+	for _, pair := range strings.Split(expression, ",") {
+		key, comparator, value, ok := splitTerm(pair)
+		if !ok {
+			return "", nil, fmt.Errorf("invalid criterion, expected a key, a comparator and a value: %v", pair)
+		}
+
+		if strings.ToUpper(key) == "OPERATOR" {
+			if comparator != "" {
+				return "", nil, fmt.Errorf("invalid operator, expected operator:and or operator:or: %v", pair)
+			}
+			op, err := validateOperator(operator, strings.ToUpper(value))
+			if err != nil {
+				return "", nil, err
+			}
+			operator = op
+			continue
+		}
+
+		if keys[strings.ToUpper(key)] {
+			return "", nil, fmt.Errorf("duplicate severity found: %v", strings.ToUpper(key))
+		}
+		keys[strings.ToUpper(key)] = true
+
+		criterion, err := parseCriterion(key, value)
+		if err != nil {
+			return "", nil, err
+		}
+		criterion.Comparator = comparator
+		if comparator == "" {
+			criterion.Comparator = COMPARATOR_GREATER_EQUAL
+			criterion.Deprecated = strings.TrimSpace(pair)
+		}
+		criteria = append(criteria, criterion)
+	}
+
+	if operator == "" {
+		return "", nil, fmt.Errorf("no operator found in expression")
+	}
+
+	return operator, criteria, nil
+}
+
+// splitTerm splits a term around its comparator, which is empty for the colon
+// syntax.
+func splitTerm(term string) (key, comparator, value string, ok bool) {
+	i := strings.IndexAny(term, "<>=:")
+	if i < 0 {
+		return "", "", "", false
+	}
+	key = strings.TrimSpace(term[:i])
+
+	if term[i] == ':' {
+		value = term[i+1:]
+	} else {
+		for _, c := range comparators {
+			if strings.HasPrefix(term[i:], c) {
+				comparator, value = c, term[i+len(c):]
+				break
+			}
+		}
+		if comparator == "" {
+			return "", "", "", false
+		}
+	}
+
+	return key, comparator, strings.TrimSpace(value), key != ""
+}
+
+func parseCriterion(key, value string) (Criterion, error) {
+	if strings.ToUpper(key) == SCORE_KEY {
+		threshold, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return Criterion{}, fmt.Errorf("error converting value to number: %v", err)
+		}
+		if threshold < 0 {
+			return Criterion{}, fmt.Errorf("validation expression can not have negative values")
+		}
+
+		return Criterion{Score: true, ThresholdScore: threshold}, nil
+	}
+
+	severity, denominator, isRatio := strings.Cut(key, "/")
+	if !isRatio {
+		threshold, err := strconv.Atoi(value)
+		if err != nil {
+			return Criterion{}, fmt.Errorf("error converting value to integer: %v", err)
+		}
+		if threshold < 0 {
+			return Criterion{}, fmt.Errorf("validation expression can not have negative values")
+		}
+
+		return Criterion{Severity: strings.ToUpper(key), Threshold: threshold}, nil
+	}
+
+	criterion := Criterion{Severity: strings.ToUpper(severity), Ratio: true}
+
+	if i := strings.Index(denominator, "["); i >= 0 && strings.HasSuffix(denominator, "]") {
+		criterion.AssetType = denominator[i+1 : len(denominator)-1]
+		denominator = denominator[:i]
+	}
+	if strings.ToUpper(denominator) != RATIO_DENOMINATOR {
+		return Criterion{}, fmt.Errorf("invalid ratio %v, expected %v/assets or %v/assets[assetType]", key, severity, severity)
+	}
+
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return Criterion{}, fmt.Errorf("error converting value to percentage: %v", err)
+	}
+	if percent < 0 {
+		return Criterion{}, fmt.Errorf("validation expression can not have negative values")
+	}
+	criterion.ThresholdPercent = percent
+
+	return criterion, nil
+}
@@ -0,0 +1,183 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fileoperator
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCriteria(t *testing.T) {
+	tests := []struct {
+		name             string
+		expression       string
+		expectedCriteria []Criterion
+		wantErr          bool
+	}{
+		{
+			name:       "CountAndRatioCriteria_Succeeds",
+			expression: "critical>=1,high/assets>5%,medium/assets[storage.googleapis.com/Bucket]>=12.5,operator:or",
+			expectedCriteria: []Criterion{
+				{Severity: "CRITICAL", Comparator: ">=", Threshold: 1},
+				{Severity: "HIGH", Comparator: ">", Ratio: true, ThresholdPercent: 5},
+				{Severity: "MEDIUM", Comparator: ">=", Ratio: true, AssetType: "storage.googleapis.com/Bucket", ThresholdPercent: 12.5},
+			},
+		},
+		{
+			name:       "SameSeverityCountAndRatio_Succeeds",
+			expression: "high>3,high/assets>=5%,operator:and",
+			expectedCriteria: []Criterion{
+				{Severity: "HIGH", Comparator: ">", Threshold: 3},
+				{Severity: "HIGH", Comparator: ">=", Ratio: true, ThresholdPercent: 5},
+			},
+		},
+		{
+			name:       "ScoreCriterion_Succeeds",
+			expression: "score >= 25.5, critical==0,operator:or",
+			expectedCriteria: []Criterion{
+				{Comparator: ">=", Score: true, ThresholdScore: 25.5},
+				{Severity: "CRITICAL", Comparator: "==", Threshold: 0},
+			},
+		},
+		{
+			name:       "LessComparators_Succeeds",
+			expression: "low<=2,medium<1,operator:and",
+			expectedCriteria: []Criterion{
+				{Severity: "LOW", Comparator: "<=", Threshold: 2},
+				{Severity: "MEDIUM", Comparator: "<", Threshold: 1},
+			},
+		},
+		{
+			name:       "DeprecatedColonSyntax_Succeeds",
+			expression: "Critical:2,high/assets:5%,Operator:or",
+			expectedCriteria: []Criterion{
+				{Severity: "CRITICAL", Comparator: ">=", Threshold: 2, Deprecated: "Critical:2"},
+				{Severity: "HIGH", Comparator: ">=", Ratio: true, ThresholdPercent: 5, Deprecated: "high/assets:5%"},
+			},
+		},
+		{
+			name:       "EmptyExpression_DefaultCriteria",
+			expression: "",
+			expectedCriteria: []Criterion{
+				{Severity: "CRITICAL", Comparator: ">=", Threshold: 1},
+				{Severity: "HIGH", Comparator: ">=", Threshold: 1},
+				{Severity: "MEDIUM", Comparator: ">=", Threshold: 1},
+				{Severity: "LOW", Comparator: ">=", Threshold: 1},
+			},
+		},
+		{
+			name:       "NegativeCount_Failure",
+			expression: "high>-1,operator:or",
+			wantErr:    true,
+		},
+		{
+			name:       "DuplicateOperator_Failure",
+			expression: "critical>2,operator:or,operator:and",
+			wantErr:    true,
+		},
+		{
+			name:       "MissingOperator_Failure",
+			expression: "critical>2,high>1",
+			wantErr:    true,
+		},
+		{
+			name:       "DuplicateSeverity_Failure",
+			expression: "critical>2,medium>3,medium>=4,operator:or",
+			wantErr:    true,
+		},
+		{
+			name:       "InvalidCount_Failure",
+			expression: "critical>invalid,operator:or",
+			wantErr:    true,
+		},
+		{
+			name:       "UnknownComparator_Failure",
+			expression: "critical=1,operator:or",
+			wantErr:    true,
+		},
+		{
+			name:       "OperatorWithComparator_Failure",
+			expression: "critical>1,operator==or",
+			wantErr:    true,
+		},
+		{
+			name:       "NegativeScore_Failure",
+			expression: "score>=-1,operator:or",
+			wantErr:    true,
+		},
+		{
+			name:       "InvalidDenominator_Failure",
+			expression: "high/modules>=5%,operator:or",
+			wantErr:    true,
+		},
+		{
+			name:       "NegativePercentage_Failure",
+			expression: "high/assets>=-5%,operator:or",
+			wantErr:    true,
+		},
+		{
+			name:       "DuplicateRatio_Failure",
+			expression: "high/assets>=5%,HIGH/assets>6%,operator:or",
+			wantErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, criteria, err := ParseCriteria(test.expression)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if diff := cmp.Diff(test.expectedCriteria, criteria); diff != "" {
+				t.Errorf("Expected criteria (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestCriterionMigrationWarning(t *testing.T) {
+	tests := []struct {
+		name            string
+		criterion       Criterion
+		expectedWarning string
+	}{
+		{
+			name:            "DeprecatedCount_Warns",
+			criterion:       Criterion{Severity: "CRITICAL", Comparator: ">=", Threshold: 2, Deprecated: "Critical:2"},
+			expectedWarning: `"Critical:2" uses the deprecated colon syntax, which fails when the threshold is reached: write "critical>=2" instead`,
+		},
+		{
+			name:            "DeprecatedRatio_Warns",
+			criterion:       Criterion{Severity: "HIGH", Comparator: ">=", Ratio: true, AssetType: "storage.googleapis.com/Bucket", ThresholdPercent: 5, Deprecated: "high/assets[storage.googleapis.com/Bucket]:5%"},
+			expectedWarning: `"high/assets[storage.googleapis.com/Bucket]:5%" uses the deprecated colon syntax, which fails when the threshold is reached: write "high/assets[storage.googleapis.com/Bucket]>=5%" instead`,
+		},
+		{
+			name:      "ExplicitComparator_NoWarning",
+			criterion: Criterion{Score: true, Comparator: ">=", ThresholdScore: 25},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.expectedWarning, test.criterion.MigrationWarning()); diff != "" {
+				t.Errorf("Expected warning (+got, -want): %v", diff)
+			}
+		})
+	}
+}
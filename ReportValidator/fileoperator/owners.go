@@ -0,0 +1,52 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fileoperator
+
+import (
+	"fmt"
+
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+// FetchOwnedViolationsFromInputFile returns the violations of the report on
+// the assets of the owner, as mapped by the CODEOWNERS-style ownership file.
+// The optional file mapping resolves the path patterns of the ownership file.
+func FetchOwnedViolationsFromInputFile(filePath, ownersFilePath, fileMappingPath *string, owner string, policy template.UnknownSeverityPolicy) ([]template.Violation, error) {
+	ownership, err := template.LoadOwnership(*ownersFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("template.LoadOwnership: %v", err)
+	}
+
+	sourceFiles, err := template.LoadFileMapping(*fileMappingPath)
+	if err != nil {
+		return nil, fmt.Errorf("template.LoadFileMapping: %v", err)
+	}
+
+	violations, err := FetchViolationDetailsFromInputFile(filePath, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := []template.Violation{}
+	for _, violation := range violations {
+		if ownership.IsOwnedBy(violation, sourceFiles, owner) {
+			owned = append(owned, violation)
+		}
+	}
+
+	return owned, nil
+}
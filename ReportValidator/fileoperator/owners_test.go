@@ -0,0 +1,61 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fileoperator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+func TestFetchOwnedViolationsFromInputFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"report.json": `{"violations": [
+			{"assetId": "bucket-1", "policyId": "P1", "severity": "HIGH", "violatedAsset": {"assetType": "storage.googleapis.com/Bucket"}},
+			{"assetId": "vm-1", "policyId": "P2", "severity": "CRITICAL", "violatedAsset": {"assetType": "compute.googleapis.com/Instance"}},
+			{"assetId": "vm-2", "policyId": "P2", "severity": "LOW", "violatedAsset": {"assetType": "compute.googleapis.com/Instance"}}
+		]}`,
+		"OWNERS":       "* @platform-team\ntype:storage.googleapis.com/Bucket @storage-team\npath:modules/sandbox/ @sandbox-team\n",
+		"mapping.json": `{"vm-2": "modules/sandbox/main.tf"}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("os.WriteFile: %v", err)
+		}
+	}
+
+	reportPath := filepath.Join(dir, "report.json")
+	ownersPath := filepath.Join(dir, "OWNERS")
+	mappingPath := filepath.Join(dir, "mapping.json")
+
+	violations, err := FetchOwnedViolationsFromInputFile(&reportPath, &ownersPath, &mappingPath, "@platform-team", template.UnknownSeverityPolicy{})
+	if err != nil {
+		t.Fatalf("FetchOwnedViolationsFromInputFile: %v", err)
+	}
+
+	expected := []template.Violation{
+		{AssetID: "vm-1", PolicyID: "P2", Severity: "CRITICAL", ViolatedAsset: template.AssetDetails{AssetType: "compute.googleapis.com/Instance"}},
+	}
+	if diff := cmp.Diff(expected, violations); diff != "" {
+		t.Errorf("Expected violations (+got, -want): %v", diff)
+	}
+}
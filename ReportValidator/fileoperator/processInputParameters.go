@@ -17,89 +17,80 @@
 package fileoperator
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
-	"strconv"
-	"strings"
-)
-
-type IACScanReport struct {
-	Response struct {
-		IACValidationReport struct {
-			Violations []struct {
-				Severity string `json:"severity"`
-			} `json:"violations"`
-		} `json:"iacValidationReport"`
-	} `json:"response"`
-}
-
-func FetchViolationFromInputFile(filePath *string) (map[string]int, error) {
-	var violationlist IACScanReport
 
-	data, err := os.ReadFile(*filePath)
-	if err != nil {
-		return nil, fmt.Errorf("os.ReadFile(%s): %v", *filePath, err)
-	}
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
 
-	err = json.Unmarshal(data, &violationlist)
+// FetchPostureTargetsFromInputFile returns the distinct posture deployment
+// target resources referenced by the violations in the report.
+func FetchPostureTargetsFromInputFile(filePath *string) ([]string, error) {
+	violationlist, err := readIACScanReport(filePath, template.UnknownSeverityPolicy{Action: template.UnknownSeverityIgnore})
 	if err != nil {
-		return nil, fmt.Errorf("json.Unmarshal: %v", err)
+		return nil, err
 	}
 
-	severityCounts := make(map[string]int)
+	seen := make(map[string]bool)
+	targets := []string{}
 
-	for _, v := range violationlist.Response.IACValidationReport.Violations {
-		severityCounts[strings.ToUpper(v.Severity)]++
+	for _, v := range violationlist.IacValidationReport.Violations {
+		target := v.ViolatedPosture.PostureDeploymentTargetResource
+		if target == "" || seen[target] {
+			continue
+		}
+		seen[target] = true
+		targets = append(targets, target)
 	}
 
-	return severityCounts, nil
+	return targets, nil
 }
 
-func ProcessExpression(expression string) (string, map[string]int, error) {
-	pairs := strings.Split(expression, ",")
-
-	if expression == "" {
-		return setDefault()
+// FetchViolationDetailsFromInputFile returns the violations of the report in
+// the order they appear in the file.
+func FetchViolationDetailsFromInputFile(filePath *string, policy template.UnknownSeverityPolicy) ([]template.Violation, error) {
+	violationlist, err := readIACScanReport(filePath, policy)
+	if err != nil {
+		return nil, err
 	}
 
-	var operator = ""
-	var userViolationCount = make(map[string]int)
-
-	for _, pair := range pairs {
-		parts := strings.Split(pair, ":")
-		key := strings.ToUpper(parts[0])
+	return violationlist.IacValidationReport.Violations, nil
+}
 
-		if key == "OPERATOR" {
-			op, err := validateOperator(operator, strings.ToUpper(parts[1]))
-			if err != nil {
-				return "", nil, err
-			}
-			operator = op
-			continue
-		}
+// ValidateInputFile parses the report in strict mode and returns every
+// structural problem found in it.
+func ValidateInputFile(filePath *string, policy template.UnknownSeverityPolicy) error {
+	data, err := os.ReadFile(*filePath)
+	if err != nil {
+		return fmt.Errorf("os.ReadFile(%s): %v", *filePath, err)
+	}
 
-		if _, ok := userViolationCount[key]; ok {
-			return "", nil, fmt.Errorf("duplicate severity found: %v", key)
-		}
+	if _, err := template.ParseReportStrict(data, policy); err != nil {
+		return fmt.Errorf("template.ParseReportStrict: %v", err)
+	}
 
-		value, err := strconv.Atoi(parts[1])
-		if err != nil {
-			return "", nil, fmt.Errorf("error converting value to integer: %v", err)
-		}
+	return nil
+}
 
-		if value < 0 {
-			return "", nil, fmt.Errorf("validation expression can not have negative values")
-		}
+// readIACScanReport parses the report and applies the unknown severity policy,
+// leaving every severity in upper case.
+func readIACScanReport(filePath *string, policy template.UnknownSeverityPolicy) (template.Responses, error) {
+	data, err := os.ReadFile(*filePath)
+	if err != nil {
+		return template.Responses{}, fmt.Errorf("os.ReadFile(%s): %v", *filePath, err)
+	}
 
-		userViolationCount[key] = value
+	violationlist, err := template.ParseReport(data)
+	if err != nil {
+		return template.Responses{}, fmt.Errorf("template.ParseReport: %v", err)
 	}
 
-	if operator == "" {
-		return "", nil, fmt.Errorf("no operator found in expression")
+	violationlist.IacValidationReport, err = template.ApplyUnknownSeverityPolicy(violationlist.IacValidationReport, policy)
+	if err != nil {
+		return template.Responses{}, fmt.Errorf("template.ApplyUnknownSeverityPolicy: %v", err)
 	}
 
-	return operator, userViolationCount, nil
+	return violationlist, nil
 }
 
 func validateOperator(finalOperator, expressionOperator string) (string, error) {
@@ -113,14 +104,3 @@ func validateOperator(finalOperator, expressionOperator string) (string, error)
 
 	return expressionOperator, nil
 }
-
-func setDefault() (string, map[string]int, error) {
-	userViolationCount := make(map[string]int)
-	userViolationCount["CRITICAL"] = 1
-	userViolationCount["HIGH"] = 1
-	userViolationCount["MEDIUM"] = 1
-	userViolationCount["LOW"] = 1
-	operator := "OR"
-
-	return operator, userViolationCount, nil
-}
@@ -18,96 +18,8 @@ package fileoperator
 
 import (
 	"testing"
-
-	"github.com/google/go-cmp/cmp"
 )
 
-func TestProcessExpression(t *testing.T) {
-	tests := []struct {
-		name                   string
-		expression             string
-		expectedSeverityCounts map[string]int
-		expectedOperator       string
-		expectedError          bool
-	}{
-		{
-			name:       "Succeeds",
-			expression: "critical:2,high:1,medium:3,operator:or",
-			expectedSeverityCounts: map[string]int{
-				"CRITICAL": 2,
-				"HIGH":     1,
-				"MEDIUM":   3,
-			},
-			expectedOperator: "OR",
-			expectedError:    false,
-		},
-		{
-			name:                   "ExpressionWithNegativeValue_Failure",
-			expression:             "high:-1,operator:or",
-			expectedSeverityCounts: nil,
-			expectedOperator:       "OR",
-			expectedError:          true,
-		},
-		{
-			name:                   "DuplicateOperatorPresent_Failure",
-			expression:             "critical:2,operator:or,operator:and",
-			expectedSeverityCounts: nil,
-			expectedOperator:       "",
-			expectedError:          true,
-		},
-		{
-			name:                   "OperatorNotPresent_Failure",
-			expression:             "critical:2,high:1,medium:3",
-			expectedSeverityCounts: nil,
-			expectedOperator:       "",
-			expectedError:          true,
-		},
-		{
-			name:                   "DuplicateSeverityPresent_Failure",
-			expression:             "critical:2,high:1,medium:3,medium:4,operator:or",
-			expectedSeverityCounts: nil,
-			expectedOperator:       "",
-			expectedError:          true,
-		},
-		{
-			name:                   "InvalidExpression_Failure",
-			expression:             "critical:invalid,high:1,medium:3",
-			expectedSeverityCounts: nil,
-			expectedOperator:       "",
-			expectedError:          true,
-		},
-		{
-			name:       "ExpressionNotPassed_SetDefault",
-			expression: "",
-			expectedSeverityCounts: map[string]int{
-				"CRITICAL": 1,
-				"HIGH":     1,
-				"MEDIUM":   1,
-				"LOW":      1,
-			},
-			expectedOperator: "OR",
-			expectedError:    false,
-		},
-	}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			t.Parallel()
-
-			operator, severityCounts, err := ProcessExpression(test.expression)
-			if (err != nil) != test.expectedError {
-				t.Fatalf("Expected error: %v, got error %v", test.expectedError, err)
-			}
-			if diff := cmp.Diff(test.expectedSeverityCounts, severityCounts); diff != "" {
-				t.Errorf("Expected severityCounts (+got, -want): %v", diff)
-			}
-			if err == nil && operator != test.expectedOperator {
-				t.Errorf("Unexpected operator: expected %v, got %v", test.expectedOperator, operator)
-			}
-		})
-	}
-}
-
 func TestValidateOperator(t *testing.T) {
 	tests := []struct {
 		name               string
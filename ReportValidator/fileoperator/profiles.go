@@ -0,0 +1,131 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fileoperator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+)
+
+// ProfileConfig is the set of named validation profiles passed as an input.
+type ProfileConfig struct {
+	DefaultProfile string    `json:"defaultProfile,omitempty"`
+	Profiles       []Profile `json:"profiles"`
+}
+
+// Profile carries the failure criteria applied to one environment.
+// Match holds glob patterns compared against the posture deployment target
+// resources of the report, e.g. "projects/prod-*".
+type Profile struct {
+	Name       string   `json:"name"`
+	Expression string   `json:"expression"`
+	Match      []string `json:"match,omitempty"`
+}
+
+func LoadProfileConfig(filePath string) (ProfileConfig, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return ProfileConfig{}, fmt.Errorf("os.ReadFile(%s): %v", filePath, err)
+	}
+
+	var config ProfileConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return ProfileConfig{}, fmt.Errorf("json.Unmarshal: %v", err)
+	}
+
+	names := make(map[string]bool)
+	for _, profile := range config.Profiles {
+		if profile.Name == "" {
+			return ProfileConfig{}, fmt.Errorf("profile without a name found")
+		}
+		if names[profile.Name] {
+			return ProfileConfig{}, fmt.Errorf("duplicate profile found: %v", profile.Name)
+		}
+		for _, pattern := range profile.Match {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return ProfileConfig{}, fmt.Errorf("invalid match pattern %q in profile %v: %v", pattern, profile.Name, err)
+			}
+		}
+		names[profile.Name] = true
+	}
+
+	if config.DefaultProfile != "" && !names[config.DefaultProfile] {
+		return ProfileConfig{}, fmt.Errorf("default profile not found: %v", config.DefaultProfile)
+	}
+
+	return config, nil
+}
+
+// SelectProfile picks the profile named by the user, or else the first
+// profile whose match patterns cover one of the report targets, or else the
+// configured default profile.
+func SelectProfile(config ProfileConfig, name string, targets []string) (Profile, error) {
+	if name != "" {
+		for _, profile := range config.Profiles {
+			if profile.Name == name {
+				return profile, nil
+			}
+		}
+		return Profile{}, fmt.Errorf("profile not found: %v", name)
+	}
+
+	for _, profile := range config.Profiles {
+		if matchesAnyTarget(profile.Match, targets) {
+			return profile, nil
+		}
+	}
+
+	if config.DefaultProfile != "" {
+		return SelectProfile(config, config.DefaultProfile, nil)
+	}
+
+	return Profile{}, fmt.Errorf("no profile matched the report targets %v and no default profile is set", targets)
+}
+
+// ResolveProfileExpression returns the failure criteria of the profile
+// selected for the report at filePath.
+func ResolveProfileExpression(filePath, profileFilePath *string, profileName string) (string, error) {
+	config, err := LoadProfileConfig(*profileFilePath)
+	if err != nil {
+		return "", fmt.Errorf("LoadProfileConfig: %v", err)
+	}
+
+	targets, err := FetchPostureTargetsFromInputFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("FetchPostureTargetsFromInputFile: %v", err)
+	}
+
+	profile, err := SelectProfile(config, profileName, targets)
+	if err != nil {
+		return "", fmt.Errorf("SelectProfile: %v", err)
+	}
+
+	return profile.Expression, nil
+}
+
+func matchesAnyTarget(patterns, targets []string) bool {
+	for _, pattern := range patterns {
+		for _, target := range targets {
+			if ok, _ := path.Match(pattern, target); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fileoperator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var testProfileConfig = ProfileConfig{
+	DefaultProfile: "dev",
+	Profiles: []Profile{
+		{Name: "dev", Expression: "critical:1,operator:or", Match: []string{"projects/dev-*"}},
+		{Name: "prod", Expression: "critical:1,high:1,medium:1,low:1,operator:or", Match: []string{"projects/prod-*", "folders/42"}},
+	},
+}
+
+func TestSelectProfile(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          ProfileConfig
+		profileName     string
+		targets         []string
+		expectedProfile Profile
+		wantErr         bool
+	}{
+		{
+			name:            "ProfileSelectedByName",
+			config:          testProfileConfig,
+			profileName:     "prod",
+			targets:         []string{"projects/dev-app"},
+			expectedProfile: testProfileConfig.Profiles[1],
+		},
+		{
+			name:            "ProfileSelectedByTarget",
+			config:          testProfileConfig,
+			targets:         []string{"folders/42"},
+			expectedProfile: testProfileConfig.Profiles[1],
+		},
+		{
+			name:            "ProfileSelectedByTargetPattern",
+			config:          testProfileConfig,
+			targets:         []string{"projects/prod-billing"},
+			expectedProfile: testProfileConfig.Profiles[1],
+		},
+		{
+			name:            "NoTargetMatched_DefaultProfile",
+			config:          testProfileConfig,
+			targets:         []string{"projects/staging-app"},
+			expectedProfile: testProfileConfig.Profiles[0],
+		},
+		{
+			name:        "UnknownProfileName_Failure",
+			config:      testProfileConfig,
+			profileName: "qa",
+			wantErr:     true,
+		},
+		{
+			name:    "NoTargetMatchedNoDefault_Failure",
+			config:  ProfileConfig{Profiles: testProfileConfig.Profiles},
+			targets: []string{"projects/staging-app"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			profile, err := SelectProfile(test.config, test.profileName, test.targets)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if diff := cmp.Diff(test.expectedProfile, profile); diff != "" {
+				t.Errorf("Expected profile (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestLoadProfileConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "ValidConfig_Succeeds",
+			content: `{"defaultProfile": "dev", "profiles": [{"name": "dev", "expression": "critical:1,operator:or"}]}`,
+		},
+		{
+			name:    "DuplicateProfile_Failure",
+			content: `{"profiles": [{"name": "dev"}, {"name": "dev"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "UnknownDefaultProfile_Failure",
+			content: `{"defaultProfile": "prod", "profiles": [{"name": "dev"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "InvalidMatchPattern_Failure",
+			content: `{"profiles": [{"name": "dev", "match": ["projects/[dev"]}]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "profiles.json")
+			if err := os.WriteFile(filePath, []byte(test.content), 0o644); err != nil {
+				t.Fatalf("os.WriteFile: %v", err)
+			}
+
+			_, err := LoadProfileConfig(filePath)
+			if (err != nil) != test.wantErr {
+				t.Errorf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+		})
+	}
+}
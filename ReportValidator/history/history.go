@@ -0,0 +1,244 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package history stores the outcome of validator runs to follow the
+// violation counts over time.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+// Record is the outcome of one validator run, stored as one JSON line.
+type Record struct {
+	Timestamp      time.Time      `json:"timestamp"`
+	CommitSHA      string         `json:"commitSha,omitempty"`
+	SeverityCounts map[string]int `json:"severityCounts"`
+	PolicyCounts   map[string]int `json:"policyCounts"`
+	Violated       bool           `json:"violated"`
+	// Filter is the canonical form of the -include, -exclude and scope
+	// filter of the run, see template.ViolationFilter.String.
+	Filter string `json:"filter,omitempty"`
+}
+
+// Regression is a count that grew since the previous record.
+type Regression struct {
+	Key      string
+	Previous int
+	Current  int
+}
+
+// NewRecord counts the violations per severity and per policy.
+func NewRecord(violations []template.Violation, violated bool, commitSHA string, now time.Time) Record {
+	record := Record{
+		Timestamp:      now.UTC(),
+		CommitSHA:      commitSHA,
+		SeverityCounts: make(map[string]int),
+		PolicyCounts:   make(map[string]int),
+		Violated:       violated,
+	}
+
+	for _, v := range violations {
+		record.SeverityCounts[strings.ToUpper(v.Severity)]++
+		record.PolicyCounts[v.PolicyID]++
+	}
+
+	return record
+}
+
+// Append adds the record at the end of the history file, creating it if
+// needed.
+func Append(filePath string, record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("json.Marshal(): %v", err)
+	}
+
+	f, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("os.OpenFile(%s): %v", filePath, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("f.Write(): %v", err)
+	}
+
+	return f.Close()
+}
+
+// Load reads the records of the history file, oldest first.
+func Load(filePath string) ([]Record, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("os.Open(%s): %v", filePath, err)
+	}
+	defer f.Close()
+
+	records := []Record{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("line %d: json.Unmarshal(): %v", line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanner.Scan(): %v", err)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+
+	return records, nil
+}
+
+// Regressions returns the severity and policy counts of the current record
+// that grew since the previous one, severities first from the most severe.
+func Regressions(previous, current Record) []Regression {
+	regressions := []Regression{}
+
+	for _, severity := range template.KnownSeverities {
+		if c, p := current.SeverityCounts[string(severity)], previous.SeverityCounts[string(severity)]; c > p {
+			regressions = append(regressions, Regression{Key: string(severity), Previous: p, Current: c})
+		}
+	}
+
+	policies := make([]string, 0, len(current.PolicyCounts))
+	for policy := range current.PolicyCounts {
+		policies = append(policies, policy)
+	}
+	sort.Strings(policies)
+	for _, policy := range policies {
+		if c, p := current.PolicyCounts[policy], previous.PolicyCounts[policy]; c > p {
+			regressions = append(regressions, Regression{Key: policy, Previous: p, Current: c})
+		}
+	}
+
+	return regressions
+}
+
+// RenderTrend writes the counts per severity of every record, flagging the
+// records whose counts grew since the previous one.
+func RenderTrend(w io.Writer, records []Record) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	header := []string{"TIMESTAMP", "COMMIT"}
+	for _, severity := range template.KnownSeverities {
+		header = append(header, string(severity))
+	}
+	header = append(header, "VERDICT", "REGRESSIONS")
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for i, record := range records {
+		row := []string{record.Timestamp.Format(time.RFC3339), record.CommitSHA}
+		for _, severity := range template.KnownSeverities {
+			row = append(row, fmt.Sprint(record.SeverityCounts[string(severity)]))
+		}
+
+		verdict := "passed"
+		if record.Violated {
+			verdict = "failed"
+		}
+		row = append(row, verdict)
+
+		regressions := []string{}
+		if i > 0 {
+			for _, r := range Regressions(records[i-1], record) {
+				regressions = append(regressions, fmt.Sprintf("%s %d->%d", r.Key, r.Previous, r.Current))
+			}
+		}
+		row = append(row, strings.Join(regressions, ", "))
+
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// LastPassing returns the last passing record of the history counting the
+// violations of the filter, so that runs with other filters sharing the
+// history file are skipped.
+func LastPassing(records []Record, filter string) (Record, bool) {
+	for i := len(records) - 1; i >= 0; i-- {
+		if !records[i].Violated && records[i].Filter == filter {
+			return records[i], true
+		}
+	}
+
+	return Record{}, false
+}
+
+// CheckComparable returns an error when the baseline counted the violations
+// of another filter than the current record, as their counts would not
+// compare.
+func CheckComparable(baseline, current Record) error {
+	if baseline.Filter != current.Filter {
+		return fmt.Errorf("the baseline was recorded with the filter %q but the current run uses %q, record a new baseline", baseline.Filter, current.Filter)
+	}
+
+	return nil
+}
+
+// LoadBaseline reads the baseline file of the ratchet mode, a single record.
+// It returns false when the file does not exist yet.
+func LoadBaseline(filePath string) (Record, bool, error) {
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return Record{}, false, nil
+	}
+	if err != nil {
+		return Record{}, false, fmt.Errorf("os.ReadFile(%s): %v", filePath, err)
+	}
+
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return Record{}, false, fmt.Errorf("json.Unmarshal(): %v", err)
+	}
+
+	return record, true, nil
+}
+
+// SaveBaseline replaces the baseline file with the record.
+func SaveBaseline(filePath string, record Record) error {
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent(): %v", err)
+	}
+
+	if err := os.WriteFile(filePath, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("os.WriteFile(%s): %v", filePath, err)
+	}
+
+	return nil
+}
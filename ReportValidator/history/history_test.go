@@ -0,0 +1,162 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package history
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+var (
+	firstRun  = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	secondRun = firstRun.Add(24 * time.Hour)
+)
+
+func TestNewRecord(t *testing.T) {
+	violations := []template.Violation{
+		{PolicyID: "P1", Severity: "HIGH"},
+		{PolicyID: "P1", Severity: "high"},
+		{PolicyID: "P2", Severity: "LOW"},
+	}
+
+	expected := Record{
+		Timestamp:      firstRun,
+		CommitSHA:      "abc123",
+		SeverityCounts: map[string]int{"HIGH": 2, "LOW": 1},
+		PolicyCounts:   map[string]int{"P1": 2, "P2": 1},
+		Violated:       true,
+	}
+
+	if diff := cmp.Diff(expected, NewRecord(violations, true, "abc123", firstRun)); diff != "" {
+		t.Errorf("Expected record (+got, -want): %v", diff)
+	}
+}
+
+func TestAppendAndLoad(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "history.jsonl")
+	records := []Record{
+		{Timestamp: secondRun, CommitSHA: "def456", SeverityCounts: map[string]int{"LOW": 1}, PolicyCounts: map[string]int{"P2": 1}},
+		{Timestamp: firstRun, CommitSHA: "abc123", SeverityCounts: map[string]int{"HIGH": 1}, PolicyCounts: map[string]int{"P1": 1}, Violated: true},
+	}
+	for _, record := range records {
+		if err := Append(filePath, record); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	loaded, err := Load(filePath)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if diff := cmp.Diff([]Record{records[1], records[0]}, loaded); diff != "" {
+		t.Errorf("Expected records oldest first (+got, -want): %v", diff)
+	}
+}
+
+func TestRegressions(t *testing.T) {
+	previous := Record{SeverityCounts: map[string]int{"HIGH": 1, "LOW": 3}, PolicyCounts: map[string]int{"P1": 1, "P2": 3}}
+	current := Record{SeverityCounts: map[string]int{"CRITICAL": 1, "HIGH": 1, "LOW": 1}, PolicyCounts: map[string]int{"P1": 1, "P2": 1, "P3": 1}}
+
+	expected := []Regression{
+		{Key: "CRITICAL", Previous: 0, Current: 1},
+		{Key: "P3", Previous: 0, Current: 1},
+	}
+
+	if diff := cmp.Diff(expected, Regressions(previous, current)); diff != "" {
+		t.Errorf("Expected regressions (+got, -want): %v", diff)
+	}
+}
+
+func TestRenderTrend(t *testing.T) {
+	records := []Record{
+		{Timestamp: firstRun, CommitSHA: "abc123", SeverityCounts: map[string]int{"HIGH": 2}, PolicyCounts: map[string]int{"P1": 2}, Violated: true},
+		{Timestamp: secondRun, CommitSHA: "def456", SeverityCounts: map[string]int{"HIGH": 1, "LOW": 1}, PolicyCounts: map[string]int{"P1": 1, "P2": 1}, Violated: true},
+	}
+
+	var b bytes.Buffer
+	if err := RenderTrend(&b, records); err != nil {
+		t.Fatalf("RenderTrend: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and 2 rows, got: %q", b.String())
+	}
+	if !strings.Contains(lines[1], "abc123") || strings.Contains(lines[1], "->") {
+		t.Errorf("Expected the first record without regression, got: %q", lines[1])
+	}
+	if !strings.HasSuffix(lines[2], "LOW 0->1, P2 0->1") {
+		t.Errorf("Expected the second record to flag its regressions, got: %q", lines[2])
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "baseline.json")
+
+	if _, found, err := LoadBaseline(filePath); err != nil || found {
+		t.Fatalf("Expected no baseline, got found: %v, err: %v", found, err)
+	}
+
+	record := Record{Timestamp: firstRun, SeverityCounts: map[string]int{"HIGH": 1}, PolicyCounts: map[string]int{"P1": 1}}
+	if err := SaveBaseline(filePath, record); err != nil {
+		t.Fatalf("SaveBaseline: %v", err)
+	}
+
+	baseline, found, err := LoadBaseline(filePath)
+	if err != nil || !found {
+		t.Fatalf("Expected a baseline, got found: %v, err: %v", found, err)
+	}
+	if diff := cmp.Diff(record, baseline); diff != "" {
+		t.Errorf("Expected baseline (+got, -want): %v", diff)
+	}
+}
+
+func TestCheckComparable(t *testing.T) {
+	baseline := Record{Filter: "include=project=prod-app;exclude="}
+
+	if err := CheckComparable(baseline, Record{Filter: "include=project=prod-app;exclude="}); err != nil {
+		t.Errorf("Expected the same filter to compare, got: %v", err)
+	}
+	if err := CheckComparable(baseline, Record{}); err == nil {
+		t.Errorf("Expected error for another filter, got nil")
+	}
+}
+
+func TestLastPassing(t *testing.T) {
+	records := []Record{
+		{CommitSHA: "abc123", Filter: "include=project=prod-app;exclude="},
+		{CommitSHA: "def456"},
+		{CommitSHA: "fed789", Filter: "include=project=prod-app;exclude=", Violated: true},
+		{CommitSHA: "ghi012", Filter: "include=project=other;exclude="},
+	}
+
+	baseline, found := LastPassing(records, "include=project=prod-app;exclude=")
+	if !found || baseline.CommitSHA != "abc123" {
+		t.Errorf("Expected the last passing record of the filter abc123, got: %v, found: %v", baseline.CommitSHA, found)
+	}
+	if _, found := LastPassing(records, "include=project=sandbox;exclude="); found {
+		t.Errorf("Expected no baseline for a filter without record")
+	}
+}
@@ -18,26 +18,189 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/mikelaramie/IACPlugin2024/ReportValidator/evaluate"
+	"github.com/mikelaramie/IACPlugin2024/ReportValidator/fileoperator"
+	"github.com/mikelaramie/IACPlugin2024/ReportValidator/history"
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+	"github.com/mikelaramie/IACPlugin2024/tfplan"
 )
 
 var (
-	filePath   = flag.String("filePath", "", "path of the json file")
-	expression = flag.String("expression", "", "condition for validation")
+	filePath     = flag.String("filePath", "", "path of the json file")
+	expression   = flag.String("expression", "", "condition for validation")
+	profile      = flag.String("profile", "", "name of the validation profile to apply")
+	profilesFile = flag.String("profiles", "", "path of the json file defining validation profiles")
+	explain      = flag.Bool("explain", false, "print the evaluation trace of the validation")
+	strict       = flag.Bool("strict", false, "fail on empty or malformed reports")
+	ownersFile   = flag.String("owners", "", "path of the CODEOWNERS-style file mapping assets to teams")
+	owner        = flag.String("owner", "", "only validate the violations on the assets of this owner")
+	fileMapping  = flag.String("fileMapping", "", "path of the json file mapping asset IDs to source files, for the path patterns of -owners")
+	project      = flag.String("project", "", "only validate the violations on the assets of this project")
+	location     = flag.String("location", "", "only validate the violations on the assets of this location")
+	labels       = flag.String("labels", "", "only validate the violations on the assets with these comma separated key=value labels")
+	include      = flag.String("include", "", "comma separated field=pattern terms the validated violations must all match")
+	exclude      = flag.String("exclude", "", "comma separated field=pattern terms excluding the violations matching any of them")
+	historyFile  = flag.String("history", "", "path of the jsonl file the outcome of the validation is appended to")
+	commitSHA    = flag.String("commitSha", "", "commit SHA recorded in the history file")
+	ratchet      = flag.Bool("ratchet", false, "fail only when a violation count grows against the baseline")
+	baselineFile = flag.String("baseline", "", "path of the json baseline file of the ratchet mode, the last passing record of -history by default")
+	assetCount   = flag.Int("assetCount", 0, "number of assets scanned, for the ratio criteria")
+	planFile     = flag.String("plan", "", "path of the terraform json plan the report was created from, counting the assets scanned for the ratio criteria")
+	scoreWeights = flag.String("scoreWeights", "", "path of the json file weighing severities, policies and asset types in the risk score criteria")
+	scoreOutput  = flag.String("scoreOutput", "", "path of the json file the risk score and its breakdown are written to")
+	unknownSev   = flag.String("unknownSeverity", template.UnknownSeverityFail, "handling of unknown severities: fail, ignore or treat-as-<severity>")
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "trend" {
+		runTrend(os.Args[2:])
+		return
+	}
+
 	flag.Parse()
 
-	isViolated, err := evaluate.IsIACScanReportViolatingSeverity(filePath, expression)
+	severityPolicy, err := template.ParseUnknownSeverityPolicy(*unknownSev)
+	if err != nil {
+		fmt.Printf("Failure occured during validation: %v", err)
+		os.Exit(99)
+	}
+
+	if *strict {
+		if err := fileoperator.ValidateInputFile(filePath, severityPolicy); err != nil {
+			fmt.Printf("Failure occured during validation: %v", err)
+			os.Exit(99)
+		}
+	}
+
+	if *profile != "" && *profilesFile == "" {
+		fmt.Printf("Failure occured during validation: -profile requires -profiles")
+		os.Exit(99)
+	}
+
+	if *profilesFile != "" {
+		if *expression != "" {
+			fmt.Printf("Failure occured during validation: -expression can not be combined with -profiles")
+			os.Exit(99)
+		}
+
+		profileExpression, err := fileoperator.ResolveProfileExpression(filePath, profilesFile, *profile)
+		if err != nil {
+			fmt.Printf("Failure occured during validation: %v", err)
+			os.Exit(99)
+		}
+		expression = &profileExpression
+	}
+
+	if *ratchet {
+		if *expression != "" {
+			fmt.Printf("Failure occured during validation: -ratchet can not be combined with -expression or -profiles")
+			os.Exit(99)
+		}
+		if *baselineFile == "" && *historyFile == "" {
+			fmt.Printf("Failure occured during validation: -ratchet requires -baseline or -history")
+			os.Exit(99)
+		}
+	}
+
+	if *owner != "" && *ownersFile == "" {
+		fmt.Printf("Failure occured during validation: -owner requires -owners")
+		os.Exit(99)
+	}
+
+	labelSelector, err := template.ParseLabelSelector(*labels)
 	if err != nil {
 		fmt.Printf("Failure occured during validation: %v", err)
 		os.Exit(99)
 	}
+	scope := template.AssetScope{Project: *project, Location: *location, Labels: labelSelector}
+
+	filter, err := template.ParseViolationFilter(*include, *exclude)
+	if err != nil {
+		fmt.Printf("Failure occured during validation: %v", err)
+		os.Exit(99)
+	}
+	filter.Include = append(filter.Include, scope.Filter().Include...)
+
+	var violations []template.Violation
+	if *owner != "" {
+		violations, err = fileoperator.FetchOwnedViolationsFromInputFile(filePath, ownersFile, fileMapping, *owner, severityPolicy)
+	} else {
+		violations, err = fileoperator.FetchViolationDetailsFromInputFile(filePath, severityPolicy)
+	}
+	if err != nil {
+		fmt.Printf("Failure occured during validation: %v", err)
+		os.Exit(99)
+	}
+	violations, err = filter.Apply(violations)
+	if err != nil {
+		fmt.Printf("Failure occured during validation: %v", err)
+		os.Exit(99)
+	}
+
+	var isViolated bool
+	record := history.NewRecord(violations, false, *commitSHA, time.Now())
+	record.Filter = filter.String()
+	if *ratchet {
+		isViolated, err = evaluateRatchet(record)
+		if err != nil {
+			fmt.Printf("Failure occured during validation: %v", err)
+			os.Exit(99)
+		}
+	} else {
+		assets, err := readAssetInventory()
+		if err != nil {
+			fmt.Printf("Failure occured during validation: %v", err)
+			os.Exit(99)
+		}
+
+		var weights template.ScoreWeights
+		if *scoreWeights != "" {
+			weights, err = template.LoadScoreWeights(*scoreWeights)
+			if err != nil {
+				fmt.Printf("Failure occured during validation: %v", err)
+				os.Exit(99)
+			}
+		}
+
+		trace, err := evaluate.ExplainViolations(*expression, violations, evaluate.Context{Assets: assets, Scoped: !filter.IsEmpty() || *owner != "", ScoreWeights: weights})
+		if err != nil {
+			fmt.Printf("Failure occured during validation: %v", err)
+			os.Exit(99)
+		}
+		for _, warning := range trace.Warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+		}
+		if *explain {
+			fmt.Print(trace)
+		}
+		isViolated = trace.Violated
+
+		if *scoreOutput != "" {
+			score := trace.Score
+			if score == nil {
+				s := template.ComputeScore(violations, weights)
+				score = &s
+			}
+			if err := writeScore(*scoreOutput, *score); err != nil {
+				fmt.Printf("Failure occured during validation: %v", err)
+				os.Exit(99)
+			}
+		}
+	}
+
+	if *historyFile != "" {
+		record.Violated = isViolated
+		if err := history.Append(*historyFile, record); err != nil {
+			fmt.Printf("Failure occured during validation: %v", err)
+			os.Exit(99)
+		}
+	}
 
 	if isViolated {
 		fmt.Printf("Validation Failed!")
@@ -46,3 +209,98 @@ func main() {
 
 	fmt.Println("Validation Succeeded!")
 }
+
+// readAssetInventory counts the assets scanned from the plan, or takes the
+// -assetCount total.
+func readAssetInventory() (evaluate.AssetInventory, error) {
+	if *planFile == "" {
+		return evaluate.AssetInventory{Total: *assetCount}, nil
+	}
+
+	plan, err := tfplan.Load(*planFile)
+	if err != nil {
+		return evaluate.AssetInventory{}, fmt.Errorf("tfplan.Load: %v", err)
+	}
+
+	assets := evaluate.AssetInventory{ByType: plan.AssetCounts()}
+	for _, count := range assets.ByType {
+		assets.Total += count
+	}
+
+	return assets, nil
+}
+
+// writeScore writes the risk score and its breakdown as JSON.
+func writeScore(filePath string, score template.Score) error {
+	data, err := json.MarshalIndent(score, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent(): %v", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("os.WriteFile(%s): %v", filePath, err)
+	}
+
+	return nil
+}
+
+// evaluateRatchet compares the severity counts of the record against the
+// baseline, and tightens the baseline file when they dropped. Without
+// baseline the run passes and its counts become the baseline.
+func evaluateRatchet(record history.Record) (bool, error) {
+	baseline, found := history.Record{}, false
+	if *baselineFile != "" {
+		var err error
+		baseline, found, err = history.LoadBaseline(*baselineFile)
+		if err != nil {
+			return false, fmt.Errorf("history.LoadBaseline: %v", err)
+		}
+	} else if _, err := os.Stat(*historyFile); err == nil {
+		records, err := history.Load(*historyFile)
+		if err != nil {
+			return false, fmt.Errorf("history.Load: %v", err)
+		}
+		baseline, found = history.LastPassing(records, record.Filter)
+	}
+
+	if !found {
+		fmt.Println("Ratchet: no baseline, recording the current counts")
+		if *baselineFile != "" {
+			return false, history.SaveBaseline(*baselineFile, record)
+		}
+		return false, nil
+	}
+
+	if err := history.CheckComparable(baseline, record); err != nil {
+		return false, fmt.Errorf("history.CheckComparable: %v", err)
+	}
+
+	result := evaluate.Ratchet(baseline.SeverityCounts, record.SeverityCounts)
+	fmt.Print(result)
+
+	if result.ShouldTighten() && *baselineFile != "" {
+		if err := history.SaveBaseline(*baselineFile, record); err != nil {
+			return false, fmt.Errorf("history.SaveBaseline: %v", err)
+		}
+	}
+
+	return result.Violated, nil
+}
+
+// runTrend renders the violation counts over time stored in a history file.
+func runTrend(args []string) {
+	flags := flag.NewFlagSet("trend", flag.ExitOnError)
+	trendHistoryFile := flags.String("history", "", "path of the history file")
+	flags.Parse(args)
+
+	records, err := history.Load(*trendHistoryFile)
+	if err != nil {
+		fmt.Printf("Failure occured during trend: %v", err)
+		os.Exit(99)
+	}
+
+	if err := history.RenderTrend(os.Stdout, records); err != nil {
+		fmt.Printf("Failure occured during trend: %v", err)
+		os.Exit(99)
+	}
+}
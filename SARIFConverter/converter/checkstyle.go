@@ -0,0 +1,84 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package converter
+
+import (
+	"fmt"
+
+	template "github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+const CHECKSTYLE_VERSION = "4.3"
+
+var checkstyleSeverities = map[template.Severity]string{
+	template.SeverityCritical: "error",
+	template.SeverityHigh:     "error",
+	template.SeverityMedium:   "warning",
+	template.SeverityLow:      "info",
+}
+
+// ToCheckstyle converts the report into a Checkstyle report with one file
+// element per source file. fileMapping maps asset IDs to the file declaring
+// them; violations of unmapped assets are grouped under their asset ID.
+func ToCheckstyle(report template.IACValidationReport, fileMapping map[string]string) (template.CheckstyleOutput, error) {
+	output := template.CheckstyleOutput{Version: CHECKSTYLE_VERSION}
+	fileIndex := make(map[string]int)
+
+	for _, violation := range report.Violations {
+		severity, err := checkstyleSeverity(violation.Severity)
+		if err != nil {
+			return template.CheckstyleOutput{}, fmt.Errorf("checkstyleSeverity(): %v", err)
+		}
+
+		fileName := sourceFile(fileMapping, violation.AssetID)
+
+		i, ok := fileIndex[fileName]
+		if !ok {
+			i = len(output.Files)
+			fileIndex[fileName] = i
+			output.Files = append(output.Files, template.CheckstyleFile{Name: fileName})
+		}
+
+		output.Files[i].Errors = append(output.Files[i].Errors, template.CheckstyleError{
+			Line:     1,
+			Severity: severity,
+			Message:  fmt.Sprintf("Asset %s of type %s has a violation, next steps: %s", violation.AssetID, violation.ViolatedAsset.AssetType, violation.NextSteps),
+			Source:   violation.PolicyID,
+		})
+	}
+
+	return output, nil
+}
+
+func checkstyleSeverity(severity string) (string, error) {
+	name, ok := checkstyleSeverities[template.ParseSeverity(severity)]
+	if !ok {
+		return "", fmt.Errorf("invalid severity: %s", severity)
+	}
+
+	return name, nil
+}
+
+// sourceFile returns the file declaring the asset, or its asset ID when the
+// asset is not mapped.
+func sourceFile(fileMapping map[string]string, assetID string) string {
+	if fileName, ok := fileMapping[assetID]; ok {
+		return fileName
+	}
+
+	return assetID
+}
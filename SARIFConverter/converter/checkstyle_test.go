@@ -0,0 +1,97 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package converter
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	template "github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+func TestToCheckstyle(t *testing.T) {
+	report := template.IACValidationReport{
+		Violations: []template.Violation{
+			{AssetID: "bucket", PolicyID: "policy1", Severity: "HIGH", NextSteps: "step1", ViolatedAsset: template.AssetDetails{AssetType: "storage.googleapis.com/Bucket"}},
+			{AssetID: "vm", PolicyID: "policy2", Severity: "LOW", NextSteps: "step2", ViolatedAsset: template.AssetDetails{AssetType: "compute.googleapis.com/Instance"}},
+			{AssetID: "network", PolicyID: "policy3", Severity: "MEDIUM", NextSteps: "step3", ViolatedAsset: template.AssetDetails{AssetType: "compute.googleapis.com/Network"}},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		report         template.IACValidationReport
+		fileMapping    map[string]string
+		expectedOutput template.CheckstyleOutput
+		wantErr        bool
+	}{
+		{
+			name:   "NoFileMapping_GroupedPerAsset",
+			report: report,
+			expectedOutput: template.CheckstyleOutput{
+				Version: CHECKSTYLE_VERSION,
+				Files: []template.CheckstyleFile{
+					{Name: "bucket", Errors: []template.CheckstyleError{
+						{Line: 1, Severity: "error", Message: "Asset bucket of type storage.googleapis.com/Bucket has a violation, next steps: step1", Source: "policy1"},
+					}},
+					{Name: "vm", Errors: []template.CheckstyleError{
+						{Line: 1, Severity: "info", Message: "Asset vm of type compute.googleapis.com/Instance has a violation, next steps: step2", Source: "policy2"},
+					}},
+					{Name: "network", Errors: []template.CheckstyleError{
+						{Line: 1, Severity: "warning", Message: "Asset network of type compute.googleapis.com/Network has a violation, next steps: step3", Source: "policy3"},
+					}},
+				},
+			},
+		},
+		{
+			name:        "FileMapping_GroupedPerFile",
+			report:      report,
+			fileMapping: map[string]string{"vm": "compute.tf", "network": "compute.tf"},
+			expectedOutput: template.CheckstyleOutput{
+				Version: CHECKSTYLE_VERSION,
+				Files: []template.CheckstyleFile{
+					{Name: "bucket", Errors: []template.CheckstyleError{
+						{Line: 1, Severity: "error", Message: "Asset bucket of type storage.googleapis.com/Bucket has a violation, next steps: step1", Source: "policy1"},
+					}},
+					{Name: "compute.tf", Errors: []template.CheckstyleError{
+						{Line: 1, Severity: "info", Message: "Asset vm of type compute.googleapis.com/Instance has a violation, next steps: step2", Source: "policy2"},
+						{Line: 1, Severity: "warning", Message: "Asset network of type compute.googleapis.com/Network has a violation, next steps: step3", Source: "policy3"},
+					}},
+				},
+			},
+		},
+		{
+			name:    "InvalidSeverityReport_Failure",
+			report:  IACValidationReportWithInvalidSeverity,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output, err := ToCheckstyle(test.report, test.fileMapping)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if diff := cmp.Diff(test.expectedOutput, output); diff != "" {
+				t.Errorf("Expected output (+got, -want): %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package converter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	template "github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+const (
+	GITLAB_SAST_SCHEMA_VERSION = "15.0.7"
+	GITLAB_TIME_FORMAT         = "2006-01-02T15:04:05"
+	IAC_TOOL_VENDOR            = "Google"
+)
+
+var gitLabSASTSeverities = map[template.Severity]string{
+	template.SeverityCritical: "Critical",
+	template.SeverityHigh:     "High",
+	template.SeverityMedium:   "Medium",
+	template.SeverityLow:      "Low",
+}
+
+var codeQualitySeverities = map[template.Severity]string{
+	template.SeverityCritical: "blocker",
+	template.SeverityHigh:     "critical",
+	template.SeverityMedium:   "major",
+	template.SeverityLow:      "minor",
+}
+
+// ToGitLabSAST converts the report into a GitLab SAST report. The scan times
+// come from the report and fall back to scanTime when it has none.
+// fileMapping maps asset IDs to the file declaring them, which GitLab links;
+// unmapped assets are located by their asset ID.
+func ToGitLabSAST(report template.Responses, scanTime time.Time, fileMapping map[string]string) (template.GitLabSASTReport, error) {
+	tool := template.GitLabScanTool{
+		ID:      IAC_TOOL_NAME,
+		Name:    IAC_TOOL_NAME,
+		Version: VERSION,
+		Vendor:  template.GitLabVendor{Name: IAC_TOOL_VENDOR},
+	}
+
+	sastReport := template.GitLabSASTReport{
+		Version: GITLAB_SAST_SCHEMA_VERSION,
+		Scan: template.GitLabScan{
+			Analyzer:  tool,
+			Scanner:   tool,
+			Type:      "sast",
+			StartTime: gitLabTime(report.CreateTime, scanTime),
+			EndTime:   gitLabTime(report.UpdateTime, scanTime),
+			Status:    "success",
+		},
+		Vulnerabilities: []template.GitLabVulnerability{},
+	}
+
+	for _, violation := range report.IacValidationReport.Violations {
+		severity, err := gitLabSeverity(gitLabSASTSeverities, violation.Severity)
+		if err != nil {
+			return template.GitLabSASTReport{}, fmt.Errorf("gitLabSeverity(): %v", err)
+		}
+
+		identifiers := []template.GitLabIdentifier{
+			{
+				Type:  "scc_iac_policy_id",
+				Name:  fmt.Sprintf("SCC IaC policy %s", violation.PolicyID),
+				Value: violation.PolicyID,
+			},
+		}
+		for _, standard := range violation.ViolatedPolicy.ComplianceStandards {
+			identifiers = append(identifiers, template.GitLabIdentifier{
+				Type:  "compliance_standard",
+				Name:  standard,
+				Value: standard,
+			})
+		}
+
+		sastReport.Vulnerabilities = append(sastReport.Vulnerabilities, template.GitLabVulnerability{
+			ID:          violationUUID(violation),
+			Name:        violation.PolicyID,
+			Description: violation.ViolatedPolicy.Description,
+			Severity:    severity,
+			Solution:    violation.NextSteps,
+			Identifiers: identifiers,
+			Location: template.GitLabLocation{
+				File:  sourceFile(fileMapping, violation.AssetID),
+				Class: violation.ViolatedAsset.AssetType,
+			},
+		})
+	}
+
+	return sastReport, nil
+}
+
+// ToGitLabCodeQuality converts the report into a GitLab Code Quality report,
+// locating the issues like ToGitLabSAST.
+func ToGitLabCodeQuality(report template.IACValidationReport, fileMapping map[string]string) ([]template.CodeQualityIssue, error) {
+	issues := []template.CodeQualityIssue{}
+
+	for _, violation := range report.Violations {
+		severity, err := gitLabSeverity(codeQualitySeverities, violation.Severity)
+		if err != nil {
+			return nil, fmt.Errorf("gitLabSeverity(): %v", err)
+		}
+
+		issues = append(issues, template.CodeQualityIssue{
+			Description: fmt.Sprintf("Asset type: %s has a violation, next steps: %s", violation.ViolatedAsset.AssetType, violation.NextSteps),
+			CheckName:   violation.PolicyID,
+			Fingerprint: violationFingerprint(violation),
+			Severity:    severity,
+			Location: template.CodeQualityLocation{
+				Path:  sourceFile(fileMapping, violation.AssetID),
+				Lines: template.CodeQualityLines{Begin: 1},
+			},
+		})
+	}
+
+	return issues, nil
+}
+
+// gitLabSeverity maps the severity of a violation to its GitLab name.
+func gitLabSeverity(severities map[template.Severity]string, severity string) (string, error) {
+	name, ok := severities[template.ParseSeverity(severity)]
+	if !ok {
+		return "", fmt.Errorf("invalid severity: %s", severity)
+	}
+
+	return name, nil
+}
+
+// violationFingerprint identifies a violation across reports by its policy
+// and asset so GitLab can track it between pipelines.
+func violationFingerprint(violation template.Violation) string {
+	sum := sha256.Sum256([]byte(violation.PolicyID + "\x00" + violation.AssetID))
+	return hex.EncodeToString(sum[:])
+}
+
+func violationUUID(violation template.Violation) string {
+	return uuidFromDigest(violationFingerprint(violation))
+}
+
+// uuidFromDigest formats the first 128 bits of a hex digest as a UUID.
+func uuidFromDigest(f string) string {
+	return fmt.Sprintf("%s-%s-%s-%s-%s", f[0:8], f[8:12], f[12:16], f[16:20], f[20:32])
+}
+
+func gitLabTime(reportTime string, fallback time.Time) string {
+	if t, err := time.Parse(time.RFC3339Nano, reportTime); err == nil {
+		return t.UTC().Format(GITLAB_TIME_FORMAT)
+	}
+
+	return fallback.UTC().Format(GITLAB_TIME_FORMAT)
+}
@@ -0,0 +1,201 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	template "github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+var gitLabTool = template.GitLabScanTool{
+	ID:      IAC_TOOL_NAME,
+	Name:    IAC_TOOL_NAME,
+	Version: VERSION,
+	Vendor:  template.GitLabVendor{Name: IAC_TOOL_VENDOR},
+}
+
+func TestToGitLabSAST(t *testing.T) {
+	scanTime := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		report         template.Responses
+		fileMapping    map[string]string
+		expectedReport template.GitLabSASTReport
+		wantErr        bool
+	}{
+		{
+			name: "ValidReport_Succeeds",
+			report: template.Responses{
+				CreateTime:          "2024-05-01T10:00:00Z",
+				IacValidationReport: IACValidationValidReport,
+			},
+			expectedReport: template.GitLabSASTReport{
+				Version: GITLAB_SAST_SCHEMA_VERSION,
+				Scan: template.GitLabScan{
+					Analyzer:  gitLabTool,
+					Scanner:   gitLabTool,
+					Type:      "sast",
+					StartTime: "2024-05-01T10:00:00",
+					EndTime:   "2024-06-01T12:00:00",
+					Status:    "success",
+				},
+				Vulnerabilities: []template.GitLabVulnerability{
+					{
+						ID:          violationUUID(IACValidationValidReport.Violations[0]),
+						Name:        "P1",
+						Description: "High-level violation message",
+						Severity:    "High",
+						Solution:    "Next steps 1",
+						Identifiers: []template.GitLabIdentifier{
+							{Type: "scc_iac_policy_id", Name: "SCC IaC policy P1", Value: "P1"},
+							{Type: "compliance_standard", Name: "Standard 1", Value: "Standard 1"},
+						},
+						Location: template.GitLabLocation{File: "Asset 1", Class: "Type 1"},
+					},
+				},
+			},
+		},
+		{
+			name:        "MappedAsset_LocatedInSourceFile",
+			report:      template.Responses{IacValidationReport: IACValidationValidReport},
+			fileMapping: map[string]string{"Asset 1": "modules/storage/main.tf"},
+			expectedReport: template.GitLabSASTReport{
+				Version: GITLAB_SAST_SCHEMA_VERSION,
+				Scan: template.GitLabScan{
+					Analyzer:  gitLabTool,
+					Scanner:   gitLabTool,
+					Type:      "sast",
+					StartTime: "2024-06-01T12:00:00",
+					EndTime:   "2024-06-01T12:00:00",
+					Status:    "success",
+				},
+				Vulnerabilities: []template.GitLabVulnerability{
+					{
+						ID:          violationUUID(IACValidationValidReport.Violations[0]),
+						Name:        "P1",
+						Description: "High-level violation message",
+						Severity:    "High",
+						Solution:    "Next steps 1",
+						Identifiers: []template.GitLabIdentifier{
+							{Type: "scc_iac_policy_id", Name: "SCC IaC policy P1", Value: "P1"},
+							{Type: "compliance_standard", Name: "Standard 1", Value: "Standard 1"},
+						},
+						Location: template.GitLabLocation{File: "modules/storage/main.tf", Class: "Type 1"},
+					},
+				},
+			},
+		},
+		{
+			name:    "InvalidSeverityReport_Failure",
+			report:  template.Responses{IacValidationReport: IACValidationReportWithInvalidSeverity},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report, err := ToGitLabSAST(test.report, scanTime, test.fileMapping)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if diff := cmp.Diff(test.expectedReport, report); diff != "" {
+				t.Errorf("Expected output (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestToGitLabCodeQuality(t *testing.T) {
+	tests := []struct {
+		name           string
+		report         template.IACValidationReport
+		fileMapping    map[string]string
+		expectedIssues []template.CodeQualityIssue
+		wantErr        bool
+	}{
+		{
+			name:   "ValidReport_Succeeds",
+			report: IACValidationValidReport,
+			expectedIssues: []template.CodeQualityIssue{
+				{
+					Description: "Asset type: Type 1 has a violation, next steps: Next steps 1",
+					CheckName:   "P1",
+					Fingerprint: violationFingerprint(IACValidationValidReport.Violations[0]),
+					Severity:    "critical",
+					Location: template.CodeQualityLocation{
+						Path:  "Asset 1",
+						Lines: template.CodeQualityLines{Begin: 1},
+					},
+				},
+			},
+		},
+		{
+			name:        "MappedAsset_LocatedInSourceFile",
+			report:      IACValidationValidReport,
+			fileMapping: map[string]string{"Asset 1": "modules/storage/main.tf"},
+			expectedIssues: []template.CodeQualityIssue{
+				{
+					Description: "Asset type: Type 1 has a violation, next steps: Next steps 1",
+					CheckName:   "P1",
+					Fingerprint: violationFingerprint(IACValidationValidReport.Violations[0]),
+					Severity:    "critical",
+					Location: template.CodeQualityLocation{
+						Path:  "modules/storage/main.tf",
+						Lines: template.CodeQualityLines{Begin: 1},
+					},
+				},
+			},
+		},
+		{
+			name:    "InvalidSeverityReport_Failure",
+			report:  IACValidationReportWithInvalidSeverity,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			issues, err := ToGitLabCodeQuality(test.report, test.fileMapping)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if diff := cmp.Diff(test.expectedIssues, issues); diff != "" {
+				t.Errorf("Expected output (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestViolationFingerprint(t *testing.T) {
+	a := template.Violation{PolicyID: "P1", AssetID: "Asset 1", Severity: "HIGH"}
+	b := template.Violation{PolicyID: "P1", AssetID: "Asset 1", Severity: "LOW"}
+	c := template.Violation{PolicyID: "P1", AssetID: "Asset 2"}
+
+	if violationFingerprint(a) != violationFingerprint(b) {
+		t.Errorf("Expected the same fingerprint for the same policy and asset")
+	}
+	if violationFingerprint(a) == violationFingerprint(c) {
+		t.Errorf("Expected different fingerprints for different assets")
+	}
+}
@@ -18,77 +18,253 @@ package converter
 
 import (
 	"fmt"
+	"slices"
 
-	template "github.com/pritiprajapati314/IACPlugin2024/SARIFConverter/template"
+	template "github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
 )
 
 const (
 	VERSION                     = "1.0.0"
 	SARIF_SCHEMA                = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
 	SARIF_VERSION               = "2.1.0"
-	IAC_TOOL_DOCUMENTATION_LINK = ""
+	IAC_TOOL_DOCUMENTATION_LINK = "https://cloud.google.com/security-command-center/docs/validate-iac"
 	IAC_TOOL_NAME               = "analyze-code-security-scc"
 )
 
+// Options configures the SARIF conversion.
+type Options struct {
+	// SplitByPostureDeployment produces one run per posture deployment
+	// instead of a single run for the whole report.
+	SplitByPostureDeployment bool
+	// HelpURITemplates maps constraint types to the template of the helpUri
+	// of their rules, see DefaultHelpURITemplates.
+	HelpURITemplates map[string]string
+	// Catalog enriches the rules and results with the owner, runbook, risk
+	// rating and tags of the local policy library.
+	Catalog template.Catalog
+	// Ownership sets the owners of the asset of each result, the source files
+	// maps asset IDs to the files declaring them for its path patterns.
+	Ownership   template.Ownership
+	SourceFiles map[string]string
+	// ScoreWeights, when set, adds the risk score of the violations of each
+	// run and its breakdown to the run properties.
+	ScoreWeights *template.ScoreWeights
+}
+
 // FromIACScanReport converts the SCC IAC validation report into SARIF format.
 func FromIACScanReport(report template.IACValidationReport) (template.SarifOutput, error) {
-	policyToViolationMap := getUniqueViolations(report.Violations)
-
-	rules, err := constructRules(policyToViolationMap)
-	if err != nil {
-		return template.SarifOutput{}, fmt.Errorf("constructRules: %v", err)
-	}
-
-	results := constructResults(report.Violations)
+	return FromIACScanReportWithOptions(report, Options{})
+}
 
+// FromIACScanReportWithOptions converts the SCC IAC validation report into
+// SARIF format as configured by opts.
+func FromIACScanReportWithOptions(report template.IACValidationReport, opts Options) (template.SarifOutput, error) {
 	sarifReport := template.SarifOutput{
 		Version: SARIF_VERSION,
 		Schema:  SARIF_SCHEMA,
-		Runs: []template.Run{
-			{
-				Note: report.Note,
-				Tool: template.Tool{
-					Driver: template.Driver{
-						Name:           IAC_TOOL_NAME,
-						Version:        VERSION,
-						InformationURI: IAC_TOOL_DOCUMENTATION_LINK,
-						Rules:          rules,
-					},
+	}
+	report.Violations = applyRiskRatings(report.Violations, opts.Catalog)
+
+	if !opts.SplitByPostureDeployment || len(report.Violations) == 0 {
+		run, err := constructRun(report.Note, report.Violations, opts)
+		if err != nil {
+			return template.SarifOutput{}, err
+		}
+		sarifReport.Runs = []template.Run{run}
+		return sarifReport, nil
+	}
+
+	for _, deploymentViolations := range groupByPostureDeployment(report.Violations) {
+		run, err := constructRun(report.Note, deploymentViolations, opts)
+		if err != nil {
+			return template.SarifOutput{}, err
+		}
+
+		posture := deploymentViolations[0].ViolatedPosture
+		if posture.PostureDeployment != "" {
+			run.AutomationDetails = &template.RunAutomationDetails{
+				ID: posture.PostureDeployment,
+				Description: &template.Message{
+					Text: fmt.Sprintf("Violations of posture %s revision %s", posture.Posture, posture.PostureRevisionID),
 				},
-				Results: results,
+			}
+			if run.Properties == nil {
+				run.Properties = &template.RunProperties{}
+			}
+			run.Properties.Posture = posture.Posture
+			run.Properties.PostureRevisionID = posture.PostureRevisionID
+			run.Properties.PostureDeploymentID = posture.PostureDeployment
+			run.Properties.PostureDeploymentTargetResource = posture.PostureDeploymentTargetResource
+		}
+
+		sarifReport.Runs = append(sarifReport.Runs, run)
+	}
+
+	return sarifReport, nil
+}
+
+func constructRun(note string, violations []template.Violation, opts Options) (template.Run, error) {
+	uniqueViolations, notifications := getUniqueViolations(violations)
+
+	rules, err := constructRules(uniqueViolations, opts)
+	if err != nil {
+		return template.Run{}, fmt.Errorf("constructRules: %v", err)
+	}
+
+	results := constructResults(violations, opts)
+
+	var invocations []template.Invocation
+	if len(notifications) > 0 {
+		invocations = []template.Invocation{{ExecutionSuccessful: true, ToolExecutionNotifications: notifications}}
+	}
+
+	var properties *template.RunProperties
+	if opts.ScoreWeights != nil {
+		score := template.ComputeScore(violations, *opts.ScoreWeights)
+		properties = &template.RunProperties{RiskScore: &score}
+	}
+
+	return template.Run{
+		Note: note,
+		Tool: template.Tool{
+			Driver: template.Driver{
+				Name:           IAC_TOOL_NAME,
+				Version:        VERSION,
+				InformationURI: IAC_TOOL_DOCUMENTATION_LINK,
+				Rules:          rules,
 			},
 		},
+		Results:     results,
+		Invocations: invocations,
+		Properties:  properties,
+	}, nil
+}
+
+// groupByPostureDeployment splits the violations per posture deployment, in
+// the order the deployments first appear in the report.
+func groupByPostureDeployment(violations []template.Violation) [][]template.Violation {
+	groups := [][]template.Violation{}
+	groupIndex := make(map[string]int)
+
+	for _, violation := range violations {
+		deployment := violation.ViolatedPosture.PostureDeployment
+		i, ok := groupIndex[deployment]
+		if !ok {
+			i = len(groups)
+			groupIndex[deployment] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], violation)
 	}
 
-	return sarifReport, nil
+	return groups
 }
 
-func getUniqueViolations(violations []template.Violation) map[string]template.Violation {
-	policyToViolationMap := make(map[string]template.Violation)
+// ruleTextFields are the rule metadata the violations of a policy should
+// agree on.
+var ruleTextFields = []struct {
+	name  string
+	field func(*template.Violation) *string
+}{
+	{"description", func(v *template.Violation) *string { return &v.ViolatedPolicy.Description }},
+	{"next steps", func(v *template.Violation) *string { return &v.NextSteps }},
+	{"constraint", func(v *template.Violation) *string { return &v.ViolatedPolicy.Constraint }},
+	{"constraint type", func(v *template.Violation) *string { return &v.ViolatedPolicy.ConstraintType }},
+}
+
+// getUniqueViolations merges the violations of each policy into the one its
+// rule is built from, in the order the policies first appear. The compliance
+// standards are merged and the highest severity wins. When the violations
+// disagree on a text field the longest value wins and the conflict is
+// reported as a warning.
+func getUniqueViolations(violations []template.Violation) ([]template.Violation, []template.Notification) {
+	uniqueViolations := []template.Violation{}
+	policyIndex := make(map[string]int)
+	conflicts := make([]map[string]bool, 0)
 
 	for _, violation := range violations {
-		policyID := violation.PolicyID
-		if _, ok := policyToViolationMap[policyID]; !ok {
-			policyToViolationMap[policyID] = violation
+		i, ok := policyIndex[violation.PolicyID]
+		if !ok {
+			policyIndex[violation.PolicyID] = len(uniqueViolations)
+			merged := violation
+			merged.ViolatedPolicy.ComplianceStandards = append([]string(nil), violation.ViolatedPolicy.ComplianceStandards...)
+			uniqueViolations = append(uniqueViolations, merged)
+			conflicts = append(conflicts, make(map[string]bool))
+			continue
+		}
+
+		merged := &uniqueViolations[i]
+
+		current, other := template.ParseSeverity(merged.Severity), template.ParseSeverity(violation.Severity)
+		if current != other {
+			conflicts[i]["severity"] = true
+			if other.Rank() > current.Rank() {
+				merged.Severity = violation.Severity
+			}
+		}
+
+		for _, f := range ruleTextFields {
+			mergedValue, value := f.field(merged), *f.field(&violation)
+			if value == "" || value == *mergedValue {
+				continue
+			}
+			if *mergedValue != "" {
+				conflicts[i][f.name] = true
+			}
+			if len(value) > len(*mergedValue) {
+				*mergedValue = value
+			}
+		}
+
+		for _, standard := range violation.ViolatedPolicy.ComplianceStandards {
+			if !slices.Contains(merged.ViolatedPolicy.ComplianceStandards, standard) {
+				merged.ViolatedPolicy.ComplianceStandards = append(merged.ViolatedPolicy.ComplianceStandards, standard)
+			}
+		}
+	}
+
+	notifications := []template.Notification{}
+	for i, violation := range uniqueViolations {
+		if conflicts[i]["severity"] {
+			notifications = append(notifications, conflictNotification(violation.PolicyID, "severity", violation.Severity))
+		}
+		for _, f := range ruleTextFields {
+			if conflicts[i][f.name] {
+				notifications = append(notifications, conflictNotification(violation.PolicyID, f.name, *f.field(&violation)))
+			}
 		}
 	}
 
-	return policyToViolationMap
+	return uniqueViolations, notifications
+}
+
+func conflictNotification(policyID, field, value string) template.Notification {
+	return template.Notification{
+		Level: "warning",
+		Message: template.Message{
+			Text: fmt.Sprintf("Policy %s has conflicting %s across its violations, using %q", policyID, field, value),
+		},
+		AssociatedRule: &template.RuleReference{ID: policyID},
+	}
 }
 
-func constructRules(policyToViolationMap map[string]template.Violation) ([]template.Rule, error) {
+func constructRules(uniqueViolations []template.Violation, opts Options) ([]template.Rule, error) {
 	rules := []template.Rule{}
 
-	for policyID, violation := range policyToViolationMap {
+	for _, violation := range uniqueViolations {
 		if !validateSeverity(violation.Severity) {
 			return nil, fmt.Errorf("validateSeverity() invalid severity: %s ", violation.Severity)
 		}
 
 		rule := template.Rule{
-			ID: policyID,
+			ID:               violation.PolicyID,
+			Name:             ruleName(violation),
+			ShortDescription: shortDescription(violation.ViolatedPolicy.Description),
 			FullDescription: template.FullDescription{
 				Text: violation.ViolatedPolicy.Description,
 			},
+			Help:    ruleHelp(violation),
+			HelpURI: helpURI(violation, opts.HelpURITemplates),
 			Properties: template.RuleProperties{
 				Severity:            violation.Severity,
 				PolicyType:          violation.ViolatedPolicy.ConstraintType,
@@ -102,13 +278,19 @@ func constructRules(policyToViolationMap map[string]template.Violation) ([]templ
 			},
 		}
 
+		if policy, ok := opts.Catalog.Lookup(violation); ok {
+			rule.Properties.Owner = policy.Owner
+			rule.Properties.RunbookURL = policy.RunbookURL
+			rule.Properties.Tags = policy.Tags
+		}
+
 		rules = append(rules, rule)
 	}
 
 	return rules, nil
 }
 
-func constructResults(violations []template.Violation) []template.Result {
+func constructResults(violations []template.Violation, opts Options) []template.Result {
 	results := []template.Result{}
 
 	for _, violation := range violations {
@@ -127,21 +309,48 @@ func constructResults(violations []template.Violation) []template.Result {
 				},
 			},
 			Properties: template.ResultProperties{
+				Severity:  violation.Severity,
 				AssetID:   violation.AssetID,
 				Asset:     violation.ViolatedAsset.Asset,
 				AssetType: violation.ViolatedAsset.AssetType,
 			},
 		}
+		if policy, ok := opts.Catalog.Lookup(violation); ok {
+			result.Properties.Owner = policy.Owner
+			result.Properties.RunbookURL = policy.RunbookURL
+			result.Properties.Tags = policy.Tags
+		}
+		result.Properties.AssetOwners = opts.Ownership.Owners(violation, opts.SourceFiles)
+
+		// Payloads that are not Cloud Asset Inventory assets are only kept
+		// raw in the asset property.
+		if asset, err := template.ParseAsset(violation.ViolatedAsset.Asset); err == nil {
+			result.Properties.AssetName = asset.Name
+			result.Properties.Project = asset.Project
+			result.Properties.Location = asset.Location
+			result.Properties.Labels = asset.Labels
+		}
 		results = append(results, result)
 	}
 
 	return results
 }
 
-func validateSeverity(severity string) bool {
-	if severity != "CRITICAL" && severity != "HIGH" && severity != "MEDIUM" && severity != "LOW" {
-		return false
+// applyRiskRatings overrides the severity of the violations with the risk
+// rating of their policy in the catalog, so that the rules, the results and
+// the risk score agree on it.
+func applyRiskRatings(violations []template.Violation, catalog template.Catalog) []template.Violation {
+	rated := make([]template.Violation, 0, len(violations))
+	for _, violation := range violations {
+		if policy, ok := catalog.Lookup(violation); ok && policy.RiskRating != "" {
+			violation.Severity = string(template.ParseSeverity(policy.RiskRating))
+		}
+		rated = append(rated, violation)
 	}
 
-	return true
+	return rated
+}
+
+func validateSeverity(severity string) bool {
+	return template.ParseSeverity(severity).IsKnown()
 }
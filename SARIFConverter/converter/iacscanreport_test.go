@@ -21,7 +21,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 
-	template "github.com/pritiprajapati314/IACPlugin2024/SARIFConverter/template"
+	template "github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
 )
 
 func TestGenerateReport(t *testing.T) {
@@ -60,16 +60,111 @@ func TestGenerateReport(t *testing.T) {
 	}
 }
 
+func TestGenerateReportSplitByPostureDeployment(t *testing.T) {
+	prodPosture := template.PostureDetails{
+		Posture:                         "organizations/1/locations/global/postures/prod",
+		PostureRevisionID:               "rev1",
+		PostureDeployment:               "organizations/1/locations/global/postureDeployments/prod",
+		PostureDeploymentTargetResource: "projects/prod",
+	}
+	devPosture := template.PostureDetails{
+		Posture:           "organizations/1/locations/global/postures/dev",
+		PostureRevisionID: "rev2",
+		PostureDeployment: "organizations/1/locations/global/postureDeployments/dev",
+	}
+	report := template.IACValidationReport{
+		Note: "Test Note",
+		Violations: []template.Violation{
+			{AssetID: "asset1", PolicyID: "policy1", Severity: "HIGH", ViolatedPosture: prodPosture},
+			{AssetID: "asset2", PolicyID: "policy2", Severity: "LOW", ViolatedPosture: devPosture},
+			{AssetID: "asset3", PolicyID: "policy1", Severity: "HIGH", ViolatedPosture: prodPosture},
+		},
+	}
+
+	output, err := FromIACScanReportWithOptions(report, Options{SplitByPostureDeployment: true})
+	if err != nil {
+		t.Fatalf("FromIACScanReportWithOptions: %v", err)
+	}
+
+	if len(output.Runs) != 2 {
+		t.Fatalf("Expected 2 runs, got: %d", len(output.Runs))
+	}
+
+	expectedRuns := []struct {
+		automationDetails *template.RunAutomationDetails
+		properties        *template.RunProperties
+		ruleIDs           []string
+		resultAssets      []string
+	}{
+		{
+			automationDetails: &template.RunAutomationDetails{
+				ID:          prodPosture.PostureDeployment,
+				Description: &template.Message{Text: "Violations of posture organizations/1/locations/global/postures/prod revision rev1"},
+			},
+			properties: &template.RunProperties{
+				Posture:                         prodPosture.Posture,
+				PostureRevisionID:               "rev1",
+				PostureDeploymentID:             prodPosture.PostureDeployment,
+				PostureDeploymentTargetResource: "projects/prod",
+			},
+			ruleIDs:      []string{"policy1"},
+			resultAssets: []string{"asset1", "asset3"},
+		},
+		{
+			automationDetails: &template.RunAutomationDetails{
+				ID:          devPosture.PostureDeployment,
+				Description: &template.Message{Text: "Violations of posture organizations/1/locations/global/postures/dev revision rev2"},
+			},
+			properties: &template.RunProperties{
+				Posture:             devPosture.Posture,
+				PostureRevisionID:   "rev2",
+				PostureDeploymentID: devPosture.PostureDeployment,
+			},
+			ruleIDs:      []string{"policy2"},
+			resultAssets: []string{"asset2"},
+		},
+	}
+
+	for i, expected := range expectedRuns {
+		run := output.Runs[i]
+
+		if diff := cmp.Diff(expected.automationDetails, run.AutomationDetails); diff != "" {
+			t.Errorf("Run %d: expected automationDetails (+got, -want): %v", i, diff)
+		}
+		if diff := cmp.Diff(expected.properties, run.Properties); diff != "" {
+			t.Errorf("Run %d: expected properties (+got, -want): %v", i, diff)
+		}
+
+		ruleIDs := []string{}
+		for _, rule := range run.Tool.Driver.Rules {
+			ruleIDs = append(ruleIDs, rule.ID)
+		}
+		if diff := cmp.Diff(expected.ruleIDs, ruleIDs); diff != "" {
+			t.Errorf("Run %d: expected rules (+got, -want): %v", i, diff)
+		}
+
+		resultAssets := []string{}
+		for _, result := range run.Results {
+			resultAssets = append(resultAssets, result.Properties.AssetID)
+		}
+		if diff := cmp.Diff(expected.resultAssets, resultAssets); diff != "" {
+			t.Errorf("Run %d: expected results (+got, -want): %v", i, diff)
+		}
+	}
+}
+
 func TestGetUniqueViolations(t *testing.T) {
 	testCases := []struct {
-		name     string
-		input    []template.Violation
-		expected map[string]template.Violation
+		name                  string
+		input                 []template.Violation
+		expected              []template.Violation
+		expectedNotifications []template.Notification
 	}{
 		{
-			name:     "NoViolations",
-			input:    []template.Violation{},
-			expected: map[string]template.Violation{},
+			name:                  "NoViolations",
+			input:                 []template.Violation{},
+			expected:              []template.Violation{},
+			expectedNotifications: []template.Notification{},
 		},
 		{
 			name: "MultipleUniqueViolations",
@@ -78,11 +173,12 @@ func TestGetUniqueViolations(t *testing.T) {
 				{PolicyID: "policy2", Severity: "violation2"},
 				{PolicyID: "policy3", Severity: "violation3"},
 			},
-			expected: map[string]template.Violation{
-				"policy1": {PolicyID: "policy1", Severity: "violation1"},
-				"policy2": {PolicyID: "policy2", Severity: "violation2"},
-				"policy3": {PolicyID: "policy3", Severity: "violation3"},
+			expected: []template.Violation{
+				{PolicyID: "policy1", Severity: "violation1"},
+				{PolicyID: "policy2", Severity: "violation2"},
+				{PolicyID: "policy3", Severity: "violation3"},
 			},
+			expectedNotifications: []template.Notification{},
 		},
 		{
 			name: "DuplicateViolations",
@@ -91,46 +187,108 @@ func TestGetUniqueViolations(t *testing.T) {
 				{PolicyID: "policy2", Severity: "violation2"},
 				{PolicyID: "policy1", Severity: "Violation1"},
 			},
-			expected: map[string]template.Violation{
-				"policy1": {PolicyID: "policy1", Severity: "violation1"},
-				"policy2": {PolicyID: "policy2", Severity: "violation2"},
+			expected: []template.Violation{
+				{PolicyID: "policy1", Severity: "violation1"},
+				{PolicyID: "policy2", Severity: "violation2"},
+			},
+			expectedNotifications: []template.Notification{},
+		},
+		{
+			name: "DuplicateViolations_MetadataAggregated",
+			input: []template.Violation{
+				{
+					PolicyID:       "policy1",
+					Severity:       "MEDIUM",
+					ViolatedPolicy: template.PolicyDetails{Description: "Short", ComplianceStandards: []string{"Standard 1"}},
+				},
+				{
+					PolicyID:       "policy1",
+					Severity:       "HIGH",
+					NextSteps:      "Next steps 1",
+					ViolatedPolicy: template.PolicyDetails{Description: "Longer description", ComplianceStandards: []string{"Standard 2", "Standard 1"}},
+				},
+			},
+			expected: []template.Violation{
+				{
+					PolicyID:       "policy1",
+					Severity:       "HIGH",
+					NextSteps:      "Next steps 1",
+					ViolatedPolicy: template.PolicyDetails{Description: "Longer description", ComplianceStandards: []string{"Standard 1", "Standard 2"}},
+				},
+			},
+			expectedNotifications: []template.Notification{
+				{
+					Level:          "warning",
+					Message:        template.Message{Text: `Policy policy1 has conflicting severity across its violations, using "HIGH"`},
+					AssociatedRule: &template.RuleReference{ID: "policy1"},
+				},
+				{
+					Level:          "warning",
+					Message:        template.Message{Text: `Policy policy1 has conflicting description across its violations, using "Longer description"`},
+					AssociatedRule: &template.RuleReference{ID: "policy1"},
+				},
 			},
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := getUniqueViolations(tc.input)
+			result, notifications := getUniqueViolations(tc.input)
 
 			if diff := cmp.Diff(tc.expected, result); diff != "" {
 				t.Errorf("Expected %v, (-want, +got)", diff)
 			}
+			if diff := cmp.Diff(tc.expectedNotifications, notifications); diff != "" {
+				t.Errorf("Expected notifications %v, (-want, +got)", diff)
+			}
 		})
 	}
 }
 
+func TestFromIACScanReport_ConflictingMetadataWarning(t *testing.T) {
+	report := template.IACValidationReport{
+		Violations: []template.Violation{
+			{PolicyID: "policy1", AssetID: "asset1", Severity: "LOW", NextSteps: "Fix it"},
+			{PolicyID: "policy1", AssetID: "asset2", Severity: "CRITICAL", NextSteps: "Fix it"},
+		},
+	}
+
+	sarifReport, err := FromIACScanReport(report)
+	if err != nil {
+		t.Fatalf("FromIACScanReport: %v", err)
+	}
+
+	run := sarifReport.Runs[0]
+	if got := run.Tool.Driver.Rules[0].Properties.Severity; got != "CRITICAL" {
+		t.Errorf("Expected rule severity CRITICAL, got: %v", got)
+	}
+	if len(run.Invocations) != 1 || len(run.Invocations[0].ToolExecutionNotifications) != 1 {
+		t.Fatalf("Expected one warning notification, got: %+v", run.Invocations)
+	}
+}
+
 func TestConstructRules(t *testing.T) {
 	testCases := []struct {
 		name     string
-		input    map[string]template.Violation
+		input    []template.Violation
 		expected []template.Rule
 	}{
 		{
 			name:     "EmptyInput",
-			input:    map[string]template.Violation{},
+			input:    []template.Violation{},
 			expected: []template.Rule{},
 		},
 		{
 			name: "MultipleViolations",
-			input: map[string]template.Violation{
-				"policy1": {
+			input: []template.Violation{
+				{
 					PolicyID:        "policy1",
 					Severity:        "HIGH",
 					ViolatedPolicy:  template.PolicyDetails{Description: "Description 1", ConstraintType: "Type 1", ComplianceStandards: []string{"Standard 1"}},
 					ViolatedPosture: template.PostureDetails{PolicySet: "Set 1", Posture: "Posture 1", PostureRevisionID: "Rev 1", PostureDeployment: "Dep 1"},
 					NextSteps:       "Next steps 1",
 				},
-				"policy2": {
+				{
 					PolicyID:       "policy2",
 					Severity:       "MEDIUM",
 					ViolatedPolicy: template.PolicyDetails{Description: "Description 2", ConstraintType: "Type 2"},
@@ -139,17 +297,14 @@ func TestConstructRules(t *testing.T) {
 			},
 			expected: []template.Rule{
 				{
-					ID:              "policy2",
-					FullDescription: template.FullDescription{Text: "Description 2"},
-					Properties: template.RuleProperties{
-						Severity:   "MEDIUM",
-						PolicyType: "Type 2",
-						NextSteps:  "Next steps 2",
+					ID:               "policy1",
+					Name:             "policy1",
+					ShortDescription: &template.MultiformatMessage{Text: "Description 1"},
+					FullDescription:  template.FullDescription{Text: "Description 1"},
+					Help: &template.MultiformatMessage{
+						Text:     "Description: Description 1\nNext steps: Next steps 1\nCompliance standards: Standard 1",
+						Markdown: "**Description**: Description 1\n\n**Next steps**: Next steps 1\n\n**Compliance standards**: Standard 1",
 					},
-				},
-				{
-					ID:              "policy1",
-					FullDescription: template.FullDescription{Text: "Description 1"},
 					Properties: template.RuleProperties{
 						Severity:            "HIGH",
 						PolicyType:          "Type 1",
@@ -161,12 +316,27 @@ func TestConstructRules(t *testing.T) {
 						NextSteps:           "Next steps 1",
 					},
 				},
+				{
+					ID:               "policy2",
+					Name:             "policy2",
+					ShortDescription: &template.MultiformatMessage{Text: "Description 2"},
+					FullDescription:  template.FullDescription{Text: "Description 2"},
+					Help: &template.MultiformatMessage{
+						Text:     "Description: Description 2\nNext steps: Next steps 2",
+						Markdown: "**Description**: Description 2\n\n**Next steps**: Next steps 2",
+					},
+					Properties: template.RuleProperties{
+						Severity:   "MEDIUM",
+						PolicyType: "Type 2",
+						NextSteps:  "Next steps 2",
+					},
+				},
 			},
 		},
 		{
 			name: "MissingFields",
-			input: map[string]template.Violation{
-				"policy3": {
+			input: []template.Violation{
+				{
 					PolicyID:        "policy3",
 					Severity:        "LOW",
 					ViolatedPolicy:  template.PolicyDetails{},
@@ -176,7 +346,12 @@ func TestConstructRules(t *testing.T) {
 			},
 			expected: []template.Rule{
 				{
-					ID: "policy3",
+					ID:   "policy3",
+					Name: "policy3",
+					Help: &template.MultiformatMessage{
+						Text:     "Next steps: Next steps 3",
+						Markdown: "**Next steps**: Next steps 3",
+					},
 					Properties: template.RuleProperties{
 						Severity:  "LOW",
 						NextSteps: "Next steps 3",
@@ -188,7 +363,7 @@ func TestConstructRules(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result, err := constructRules(tc.input)
+			result, err := constructRules(tc.input, Options{})
 			if err != nil {
 				t.Fatalf("constructRules(%v) failed: %v", tc.input, err)
 			}
@@ -291,7 +466,7 @@ func TestConstructResults(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := constructResults(tc.input)
+			result := constructResults(tc.input, Options{})
 
 			if diff := cmp.Diff(tc.expected, result); diff != "" {
 				t.Errorf("Expected %v, (-want, +got)", diff)
@@ -299,3 +474,83 @@ func TestConstructResults(t *testing.T) {
 		})
 	}
 }
+
+func TestFromIACScanReportWithOptions_Catalog(t *testing.T) {
+	catalog := template.Catalog{Policies: []template.CatalogPolicy{
+		{PolicyID: "P1", Owner: "team-a", RunbookURL: "https://runbooks/p1", RiskRating: "critical", Tags: []string{"pci"}},
+	}}
+
+	sarifReport, err := FromIACScanReportWithOptions(IACValidationValidReport, Options{Catalog: catalog, ScoreWeights: &template.DefaultScoreWeights})
+	if err != nil {
+		t.Fatalf("FromIACScanReportWithOptions: %v", err)
+	}
+
+	run := sarifReport.Runs[0]
+	expectedRuleProperties := IACValidSarifOutput.Runs[0].Tool.Driver.Rules[0].Properties
+	expectedRuleProperties.Severity = "CRITICAL"
+	expectedRuleProperties.Owner = "team-a"
+	expectedRuleProperties.RunbookURL = "https://runbooks/p1"
+	expectedRuleProperties.Tags = []string{"pci"}
+	if diff := cmp.Diff(expectedRuleProperties, run.Tool.Driver.Rules[0].Properties); diff != "" {
+		t.Errorf("Expected rule properties (+got, -want): %v", diff)
+	}
+
+	expectedResultProperties := IACValidSarifOutput.Runs[0].Results[0].Properties
+	expectedResultProperties.Severity = "CRITICAL"
+	expectedResultProperties.Owner = "team-a"
+	expectedResultProperties.RunbookURL = "https://runbooks/p1"
+	expectedResultProperties.Tags = []string{"pci"}
+	if diff := cmp.Diff(expectedResultProperties, run.Results[0].Properties); diff != "" {
+		t.Errorf("Expected result properties (+got, -want): %v", diff)
+	}
+
+	if diff := cmp.Diff(map[string]float64{"CRITICAL": 10}, run.Properties.RiskScore.BySeverity); diff != "" {
+		t.Errorf("Expected the risk score of the risk rating (+got, -want): %v", diff)
+	}
+}
+
+func TestConstructResults_AssetPayload(t *testing.T) {
+	asset := `{"name": "//storage.googleapis.com/b1", "resource": {"parent": "//cloudresourcemanager.googleapis.com/projects/p1", "data": {"location": "EU", "labels": {"env": "prod"}}}}`
+	violations := []template.Violation{
+		{PolicyID: "policy1", AssetID: "asset1", ViolatedAsset: template.AssetDetails{Asset: asset, AssetType: "storage.googleapis.com/Bucket"}},
+	}
+
+	expected := template.ResultProperties{
+		AssetID:   "asset1",
+		AssetType: "storage.googleapis.com/Bucket",
+		Asset:     asset,
+		AssetName: "//storage.googleapis.com/b1",
+		Project:   "p1",
+		Location:  "EU",
+		Labels:    map[string]string{"env": "prod"},
+	}
+
+	results := constructResults(violations, Options{})
+	if diff := cmp.Diff(expected, results[0].Properties); diff != "" {
+		t.Errorf("Expected result properties (+got, -want): %v", diff)
+	}
+
+	rows, err := ToTable(template.IACValidationReport{Violations: violations}, TableOptions{Columns: []string{COLUMN_PROJECT, COLUMN_LOCATION, COLUMN_LABELS}})
+	if err != nil {
+		t.Fatalf("ToTable: %v", err)
+	}
+	if diff := cmp.Diff([][]string{{"project", "location", "labels"}, {"p1", "EU", "env=prod"}}, rows); diff != "" {
+		t.Errorf("Expected rows (+got, -want): %v", diff)
+	}
+}
+
+func TestFromIACScanReportWithOptions_RiskScore(t *testing.T) {
+	sarifReport, err := FromIACScanReportWithOptions(IACValidationValidReport, Options{ScoreWeights: &template.DefaultScoreWeights})
+	if err != nil {
+		t.Fatalf("FromIACScanReportWithOptions: %v", err)
+	}
+
+	expectedScore := template.ComputeScore(IACValidationValidReport.Violations, template.DefaultScoreWeights)
+	run := sarifReport.Runs[0]
+	if run.Properties == nil {
+		t.Fatalf("Expected run properties with the risk score, got none")
+	}
+	if diff := cmp.Diff(&expectedScore, run.Properties.RiskScore); diff != "" {
+		t.Errorf("Expected risk score (+got, -want): %v", diff)
+	}
+}
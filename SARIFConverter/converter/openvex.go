@@ -0,0 +1,117 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package converter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	template "github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+const (
+	OPENVEX_CONTEXT = "https://openvex.dev/ns/v0.2.0"
+	// OpenVEX has no dedicated status for accepted risks: the violation
+	// affects the asset and the action statement records the decision.
+	OPENVEX_STATUS_AFFECTED = "affected"
+)
+
+// ToOpenVEX emits one accepted-risk statement per violation covered by a
+// waiver. Violations without a waiver are left out of the document, which
+// OpenVEX requires an author for.
+func ToOpenVEX(report template.IACValidationReport, waivers []template.Waiver, author string, now time.Time) (template.OpenVEXDocument, error) {
+	if strings.TrimSpace(author) == "" {
+		return template.OpenVEXDocument{}, fmt.Errorf("the document must have an author")
+	}
+
+	for _, waiver := range waivers {
+		if waiver.PolicyID == "" {
+			return template.OpenVEXDocument{}, fmt.Errorf("waiver without policyId found")
+		}
+		if waiver.Justification == "" || waiver.Author == "" {
+			return template.OpenVEXDocument{}, fmt.Errorf("waiver of policy %s must have a justification and an author", waiver.PolicyID)
+		}
+		if err := template.ValidateGlob(waiver.AssetID); err != nil {
+			return template.OpenVEXDocument{}, fmt.Errorf("invalid assetId in waiver of policy %s: %v", waiver.PolicyID, err)
+		}
+		if waiver.Timestamp != "" {
+			if _, err := time.Parse(time.RFC3339, waiver.Timestamp); err != nil {
+				return template.OpenVEXDocument{}, fmt.Errorf("invalid timestamp %q in waiver of policy %s, expected RFC 3339: %v", waiver.Timestamp, waiver.PolicyID, err)
+			}
+		}
+	}
+
+	timestamp := now.UTC().Format(time.RFC3339)
+	statements := []template.OpenVEXStatement{}
+	digest := sha256.New()
+
+	for _, violation := range report.Violations {
+		waiver, ok := findWaiver(violation, waivers)
+		if !ok {
+			continue
+		}
+
+		statementTime := waiver.Timestamp
+		if statementTime == "" {
+			statementTime = timestamp
+		}
+
+		statements = append(statements, template.OpenVEXStatement{
+			Vulnerability: template.OpenVEXVulnerability{
+				Name:    violation.PolicyID,
+				Aliases: violation.ViolatedPolicy.ComplianceStandards,
+			},
+			Products:        []template.OpenVEXProduct{{ID: violation.AssetID}},
+			Status:          OPENVEX_STATUS_AFFECTED,
+			StatusNotes:     fmt.Sprintf("Risk accepted by %s", waiver.Author),
+			ActionStatement: waiver.Justification,
+			Timestamp:       statementTime,
+		})
+		fmt.Fprintf(digest, "%s\x00%s\x00", violation.PolicyID, violation.AssetID)
+	}
+
+	fmt.Fprint(digest, timestamp)
+
+	return template.OpenVEXDocument{
+		Context:    OPENVEX_CONTEXT,
+		ID:         fmt.Sprintf("urn:uuid:%s", uuidFromDigest(hex.EncodeToString(digest.Sum(nil)))),
+		Author:     author,
+		Timestamp:  timestamp,
+		Version:    1,
+		Tooling:    fmt.Sprintf("%s/%s", IAC_TOOL_NAME, VERSION),
+		Statements: statements,
+	}, nil
+}
+
+func findWaiver(violation template.Violation, waivers []template.Waiver) (template.Waiver, bool) {
+	for _, waiver := range waivers {
+		if waiver.PolicyID != violation.PolicyID {
+			continue
+		}
+		if waiver.AssetID == "" {
+			return waiver, true
+		}
+		if ok, _ := template.MatchGlob(waiver.AssetID, violation.AssetID); ok {
+			return waiver, true
+		}
+	}
+
+	return template.Waiver{}, false
+}
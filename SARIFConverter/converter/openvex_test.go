@@ -0,0 +1,125 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	template "github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+func TestToOpenVEX(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	report := template.IACValidationReport{
+		Violations: []template.Violation{
+			{AssetID: "//storage.googleapis.com/logs", PolicyID: "policy1", ViolatedPolicy: template.PolicyDetails{ComplianceStandards: []string{"CIS 2.0 5.1"}}},
+			{AssetID: "//storage.googleapis.com/data", PolicyID: "policy1"},
+			{AssetID: "//compute.googleapis.com/projects/p/zones/z/instances/vm", PolicyID: "policy2"},
+			{AssetID: "//compute.googleapis.com/projects/p/zones/z/instances/vm", PolicyID: "policy3"},
+		},
+	}
+
+	tests := []struct {
+		name               string
+		waivers            []template.Waiver
+		expectedStatements []template.OpenVEXStatement
+		wantErr            bool
+	}{
+		{
+			name: "MatchingWaivers_StatementsEmitted",
+			waivers: []template.Waiver{
+				{PolicyID: "policy1", AssetID: "//storage.googleapis.com/log*", Justification: "Public logs bucket", Author: "alice@example.com", Timestamp: "2024-05-01T00:00:00Z"},
+				{PolicyID: "policy2", Justification: "Legacy VM", Author: "bob@example.com"},
+				{PolicyID: "policy3", AssetID: "//compute.googleapis.com/projects/p/*", Justification: "Sandbox project", Author: "bob@example.com"},
+			},
+			expectedStatements: []template.OpenVEXStatement{
+				{
+					Vulnerability:   template.OpenVEXVulnerability{Name: "policy1", Aliases: []string{"CIS 2.0 5.1"}},
+					Products:        []template.OpenVEXProduct{{ID: "//storage.googleapis.com/logs"}},
+					Status:          OPENVEX_STATUS_AFFECTED,
+					StatusNotes:     "Risk accepted by alice@example.com",
+					ActionStatement: "Public logs bucket",
+					Timestamp:       "2024-05-01T00:00:00Z",
+				},
+				{
+					Vulnerability:   template.OpenVEXVulnerability{Name: "policy2"},
+					Products:        []template.OpenVEXProduct{{ID: "//compute.googleapis.com/projects/p/zones/z/instances/vm"}},
+					Status:          OPENVEX_STATUS_AFFECTED,
+					StatusNotes:     "Risk accepted by bob@example.com",
+					ActionStatement: "Legacy VM",
+					Timestamp:       "2024-06-01T12:00:00Z",
+				},
+				{
+					Vulnerability:   template.OpenVEXVulnerability{Name: "policy3"},
+					Products:        []template.OpenVEXProduct{{ID: "//compute.googleapis.com/projects/p/zones/z/instances/vm"}},
+					Status:          OPENVEX_STATUS_AFFECTED,
+					StatusNotes:     "Risk accepted by bob@example.com",
+					ActionStatement: "Sandbox project",
+					Timestamp:       "2024-06-01T12:00:00Z",
+				},
+			},
+		},
+		{
+			name:               "NoWaivers_NoStatements",
+			expectedStatements: []template.OpenVEXStatement{},
+		},
+		{
+			name:    "WaiverWithoutJustification_Failure",
+			waivers: []template.Waiver{{PolicyID: "policy1", Author: "alice@example.com"}},
+			wantErr: true,
+		},
+		{
+			name:    "WaiverWithInvalidTimestamp_Failure",
+			waivers: []template.Waiver{{PolicyID: "policy1", Justification: "Legacy", Author: "alice@example.com", Timestamp: "2024-05-01"}},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			document, err := ToOpenVEX(report, test.waivers, "security@example.com", now)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			expected := template.OpenVEXDocument{
+				Context:    OPENVEX_CONTEXT,
+				Author:     "security@example.com",
+				Timestamp:  "2024-06-01T12:00:00Z",
+				Version:    1,
+				Tooling:    IAC_TOOL_NAME + "/" + VERSION,
+				Statements: test.expectedStatements,
+			}
+			if diff := cmp.Diff(expected, document, cmpopts.IgnoreFields(template.OpenVEXDocument{}, "ID")); diff != "" {
+				t.Errorf("Expected document (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestToOpenVEX_WithoutAuthor(t *testing.T) {
+	if _, err := ToOpenVEX(template.IACValidationReport{}, nil, " ", time.Now()); err == nil {
+		t.Errorf("Expected an error for a document without author")
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package converter
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	template "github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+// DefaultHelpURITemplates maps constraint types to the documentation of their
+// constraints. Templates may use the {constraint}, {constraintType} and
+// {policyId} placeholders.
+var DefaultHelpURITemplates = map[string]string{
+	"ORG_POLICY":                              "https://cloud.google.com/resource-manager/docs/organization-policy/org-policy-constraints",
+	"ORG_POLICY_CUSTOM":                       "https://cloud.google.com/resource-manager/docs/organization-policy/creating-managing-custom-constraints",
+	"SECURITY_HEALTH_ANALYTICS_MODULE":        "https://cloud.google.com/security-command-center/docs/concepts-vulnerabilities-findings",
+	"SECURITY_HEALTH_ANALYTICS_CUSTOM_MODULE": "https://cloud.google.com/security-command-center/docs/custom-modules-sha-overview",
+}
+
+// ruleName prefers the constraint, which is readable, over the policy ID.
+func ruleName(violation template.Violation) string {
+	if violation.ViolatedPolicy.Constraint != "" {
+		return violation.ViolatedPolicy.Constraint
+	}
+
+	return violation.PolicyID
+}
+
+// shortDescription is the first sentence of the policy description.
+func shortDescription(description string) *template.MultiformatMessage {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return nil
+	}
+
+	if i := strings.Index(description, ". "); i >= 0 {
+		description = description[:i+1]
+	}
+
+	return &template.MultiformatMessage{Text: description}
+}
+
+func ruleHelp(violation template.Violation) *template.MultiformatMessage {
+	policy := violation.ViolatedPolicy
+
+	var text, markdown []string
+	add := func(label, textValue, markdownValue string) {
+		text = append(text, fmt.Sprintf("%s: %s", label, textValue))
+		markdown = append(markdown, fmt.Sprintf("**%s**: %s", label, markdownValue))
+	}
+
+	if policy.Description != "" {
+		add("Description", policy.Description, policy.Description)
+	}
+	if violation.NextSteps != "" {
+		add("Next steps", violation.NextSteps, violation.NextSteps)
+	}
+	if policy.Constraint != "" {
+		constraint := fmt.Sprintf("`%s`", policy.Constraint)
+		if policy.ConstraintType != "" {
+			constraint = fmt.Sprintf("%s (%s)", constraint, policy.ConstraintType)
+		}
+		add("Constraint", strings.ReplaceAll(constraint, "`", ""), constraint)
+	}
+	if len(policy.ComplianceStandards) > 0 {
+		standards := strings.Join(policy.ComplianceStandards, ", ")
+		add("Compliance standards", standards, standards)
+	}
+
+	if len(text) == 0 {
+		return nil
+	}
+
+	return &template.MultiformatMessage{
+		Text:     strings.Join(text, "\n"),
+		Markdown: strings.Join(markdown, "\n\n"),
+	}
+}
+
+// helpURI resolves the template of the constraint type of the violation,
+// looking up helpURITemplates before DefaultHelpURITemplates. The values are
+// escaped, so IDs such as organizations/123/customConstraints/custom.x stay
+// in one path segment or query parameter.
+func helpURI(violation template.Violation, helpURITemplates map[string]string) string {
+	constraintType := violation.ViolatedPolicy.ConstraintType
+
+	uriTemplate, ok := helpURITemplates[constraintType]
+	if !ok {
+		uriTemplate = DefaultHelpURITemplates[constraintType]
+	}
+
+	return strings.NewReplacer(
+		"{constraint}", url.PathEscape(violation.ViolatedPolicy.Constraint),
+		"{constraintType}", url.PathEscape(constraintType),
+		"{policyId}", url.PathEscape(violation.PolicyID),
+	).Replace(uriTemplate)
+}
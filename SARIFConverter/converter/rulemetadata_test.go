@@ -0,0 +1,135 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package converter
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	template "github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+func TestHelpURI(t *testing.T) {
+	tests := []struct {
+		name             string
+		violation        template.Violation
+		helpURITemplates map[string]string
+		expectedURI      string
+	}{
+		{
+			name:        "OrgPolicy_DefaultTemplate",
+			violation:   template.Violation{ViolatedPolicy: template.PolicyDetails{Constraint: "storage.uniformBucketLevelAccess", ConstraintType: "ORG_POLICY"}},
+			expectedURI: DefaultHelpURITemplates["ORG_POLICY"],
+		},
+		{
+			name:      "CustomModule_ConfiguredTemplate",
+			violation: template.Violation{PolicyID: "policy1", ViolatedPolicy: template.PolicyDetails{Constraint: "bucketLogging", ConstraintType: "SECURITY_HEALTH_ANALYTICS_CUSTOM_MODULE"}},
+			helpURITemplates: map[string]string{
+				"SECURITY_HEALTH_ANALYTICS_CUSTOM_MODULE": "https://wiki.example.com/sha/{constraint}?policy={policyId}",
+			},
+			expectedURI: "https://wiki.example.com/sha/bucketLogging?policy=policy1",
+		},
+		{
+			name: "ReservedCharacters_Escaped",
+			violation: template.Violation{PolicyID: "public buckets?", ViolatedPolicy: template.PolicyDetails{
+				Constraint: "organizations/123/customConstraints/custom.bucketLogging", ConstraintType: "ORG_POLICY_CUSTOM",
+			}},
+			helpURITemplates: map[string]string{
+				"ORG_POLICY_CUSTOM": "https://wiki.example.com/custom/{constraint}?policy={policyId}",
+			},
+			expectedURI: "https://wiki.example.com/custom/organizations%2F123%2FcustomConstraints%2Fcustom.bucketLogging?policy=public%20buckets%3F",
+		},
+		{
+			name:        "UnknownConstraintType_NoURI",
+			violation:   template.Violation{ViolatedPolicy: template.PolicyDetails{ConstraintType: "REGO_POLICY"}},
+			expectedURI: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if uri := helpURI(test.violation, test.helpURITemplates); uri != test.expectedURI {
+				t.Errorf("Expected helpUri: %v, got: %v", test.expectedURI, uri)
+			}
+		})
+	}
+}
+
+func TestRuleHelp(t *testing.T) {
+	tests := []struct {
+		name         string
+		violation    template.Violation
+		expectedHelp *template.MultiformatMessage
+	}{
+		{
+			name: "AllFields",
+			violation: template.Violation{
+				NextSteps: "Enable uniform bucket-level access.",
+				ViolatedPolicy: template.PolicyDetails{
+					Description:         "Buckets must use uniform access. Fine-grained ACLs are not allowed.",
+					Constraint:          "storage.uniformBucketLevelAccess",
+					ConstraintType:      "ORG_POLICY",
+					ComplianceStandards: []string{"CIS 2.0 5.2", "NIST 800-53 AC-3"},
+				},
+			},
+			expectedHelp: &template.MultiformatMessage{
+				Text: "Description: Buckets must use uniform access. Fine-grained ACLs are not allowed.\n" +
+					"Next steps: Enable uniform bucket-level access.\n" +
+					"Constraint: storage.uniformBucketLevelAccess (ORG_POLICY)\n" +
+					"Compliance standards: CIS 2.0 5.2, NIST 800-53 AC-3",
+				Markdown: "**Description**: Buckets must use uniform access. Fine-grained ACLs are not allowed.\n\n" +
+					"**Next steps**: Enable uniform bucket-level access.\n\n" +
+					"**Constraint**: `storage.uniformBucketLevelAccess` (ORG_POLICY)\n\n" +
+					"**Compliance standards**: CIS 2.0 5.2, NIST 800-53 AC-3",
+			},
+		},
+		{
+			name:         "NoFields",
+			violation:    template.Violation{},
+			expectedHelp: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.expectedHelp, ruleHelp(test.violation)); diff != "" {
+				t.Errorf("Expected help (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestShortDescription(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		expected    *template.MultiformatMessage
+	}{
+		{name: "FirstSentence", description: "Buckets must use uniform access. Fine-grained ACLs are not allowed.", expected: &template.MultiformatMessage{Text: "Buckets must use uniform access."}},
+		{name: "SingleSentence", description: "Buckets must use uniform access", expected: &template.MultiformatMessage{Text: "Buckets must use uniform access"}},
+		{name: "Empty", description: " ", expected: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.expected, shortDescription(test.description)); diff != "" {
+				t.Errorf("Expected short description (+got, -want): %v", diff)
+			}
+		})
+	}
+}
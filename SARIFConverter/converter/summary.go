@@ -0,0 +1,61 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package converter
+
+import (
+	"sort"
+
+	template "github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+// UNOWNED groups the violations on assets without owner.
+const UNOWNED = "(unowned)"
+
+// ToOwnerSummary counts the violations of the report per owner of the violated
+// assets, sorted by owner with the unowned assets last. A violation on an
+// asset with several owners counts for each of them.
+func ToOwnerSummary(report template.IACValidationReport, ownership template.Ownership, sourceFiles map[string]string) []template.OwnerSummary {
+	summaries := []template.OwnerSummary{}
+	ownerIndex := make(map[string]int)
+
+	for _, violation := range report.Violations {
+		owners := ownership.Owners(violation, sourceFiles)
+		if len(owners) == 0 {
+			owners = []string{UNOWNED}
+		}
+
+		for _, owner := range owners {
+			i, ok := ownerIndex[owner]
+			if !ok {
+				i = len(summaries)
+				ownerIndex[owner] = i
+				summaries = append(summaries, template.OwnerSummary{Owner: owner, Severities: make(map[string]int)})
+			}
+			summaries[i].Total++
+			summaries[i].Severities[string(template.ParseSeverity(violation.Severity))]++
+		}
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		if (summaries[i].Owner == UNOWNED) != (summaries[j].Owner == UNOWNED) {
+			return summaries[j].Owner == UNOWNED
+		}
+		return summaries[i].Owner < summaries[j].Owner
+	})
+
+	return summaries
+}
@@ -0,0 +1,43 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package converter
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	template "github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+func TestToOwnerSummary(t *testing.T) {
+	ownership := template.Ownership{Rules: []template.OwnershipRule{
+		{Pattern: "type:type1", Owners: []string{"@team-b", "@team-a"}},
+		{Pattern: "asset3", Owners: []string{"@team-a"}},
+	}}
+
+	expected := []template.OwnerSummary{
+		{Owner: "@team-a", Total: 2, Severities: map[string]int{"CRITICAL": 1, "HIGH": 1}},
+		{Owner: "@team-b", Total: 1, Severities: map[string]int{"CRITICAL": 1}},
+		{Owner: UNOWNED, Total: 1, Severities: map[string]int{"LOW": 1}},
+	}
+
+	summary := ToOwnerSummary(tabularReport, ownership, nil)
+	if diff := cmp.Diff(expected, summary); diff != "" {
+		t.Errorf("Expected summary (+got, -want): %v", diff)
+	}
+}
@@ -0,0 +1,216 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package converter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	template "github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+const (
+	COLUMN_ASSET_ID             = "assetId"
+	COLUMN_ASSET_TYPE           = "assetType"
+	COLUMN_POLICY_ID            = "policyId"
+	COLUMN_SEVERITY             = "severity"
+	COLUMN_CONSTRAINT           = "constraint"
+	COLUMN_COMPLIANCE_STANDARDS = "complianceStandards"
+	COLUMN_POSTURE              = "posture"
+	COLUMN_NEXT_STEPS           = "nextSteps"
+	COLUMN_ASSET_NAME           = "assetName"
+	COLUMN_PROJECT              = "project"
+	COLUMN_LOCATION             = "location"
+	COLUMN_LABELS               = "labels"
+)
+
+// DefaultColumns are the columns exported when none are selected. The columns
+// extracted from the asset payload are only exported on demand.
+var DefaultColumns = []string{
+	COLUMN_ASSET_ID,
+	COLUMN_ASSET_TYPE,
+	COLUMN_POLICY_ID,
+	COLUMN_SEVERITY,
+	COLUMN_CONSTRAINT,
+	COLUMN_COMPLIANCE_STANDARDS,
+	COLUMN_POSTURE,
+	COLUMN_NEXT_STEPS,
+}
+
+var columnValues = map[string]func(template.Violation, template.Asset) string{
+	COLUMN_ASSET_ID:   func(v template.Violation, _ template.Asset) string { return v.AssetID },
+	COLUMN_ASSET_TYPE: func(v template.Violation, _ template.Asset) string { return v.ViolatedAsset.AssetType },
+	COLUMN_POLICY_ID:  func(v template.Violation, _ template.Asset) string { return v.PolicyID },
+	COLUMN_SEVERITY:   func(v template.Violation, _ template.Asset) string { return v.Severity },
+	COLUMN_CONSTRAINT: func(v template.Violation, _ template.Asset) string { return v.ViolatedPolicy.Constraint },
+	COLUMN_COMPLIANCE_STANDARDS: func(v template.Violation, _ template.Asset) string {
+		return strings.Join(v.ViolatedPolicy.ComplianceStandards, ", ")
+	},
+	COLUMN_POSTURE:    func(v template.Violation, _ template.Asset) string { return v.ViolatedPosture.Posture },
+	COLUMN_NEXT_STEPS: func(v template.Violation, _ template.Asset) string { return v.NextSteps },
+	COLUMN_ASSET_NAME: func(_ template.Violation, a template.Asset) string { return a.Name },
+	COLUMN_PROJECT:    func(_ template.Violation, a template.Asset) string { return a.Project },
+	COLUMN_LOCATION:   func(_ template.Violation, a template.Asset) string { return a.Location },
+	COLUMN_LABELS: func(_ template.Violation, a template.Asset) string {
+		labels := []string{}
+		for key, value := range a.Labels {
+			labels = append(labels, key+"="+value)
+		}
+		sort.Strings(labels)
+		return strings.Join(labels, ", ")
+	},
+}
+
+// assetColumns are the columns extracted from the asset payload.
+var assetColumns = map[string]bool{
+	COLUMN_ASSET_NAME: true,
+	COLUMN_PROJECT:    true,
+	COLUMN_LOCATION:   true,
+	COLUMN_LABELS:     true,
+}
+
+// tableViolation is a violation with its asset, decoded once for all the
+// columns and sort keys reading it.
+type tableViolation struct {
+	violation template.Violation
+	asset     template.Asset
+}
+
+// TableOptions selects the columns and the order of the rows of a tabular export.
+type TableOptions struct {
+	Columns []string
+	// GroupBy keeps the rows sharing the value of this column together.
+	GroupBy string
+	// SortBy orders the rows, within their group when GroupBy is set.
+	// Severities are ordered from the most to the least severe.
+	SortBy []string
+}
+
+// ToTable converts the violations of the report into rows, the first of
+// which holds the column names.
+func ToTable(report template.IACValidationReport, opts TableOptions) ([][]string, error) {
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = DefaultColumns
+	}
+
+	sortKeys := opts.SortBy
+	if opts.GroupBy != "" {
+		sortKeys = append([]string{opts.GroupBy}, opts.SortBy...)
+	}
+
+	readsAsset := false
+	for _, column := range append(append([]string{}, columns...), sortKeys...) {
+		if _, ok := columnValues[column]; !ok {
+			return nil, fmt.Errorf("unknown column: %v", column)
+		}
+		readsAsset = readsAsset || assetColumns[column]
+	}
+
+	violations := make([]tableViolation, 0, len(report.Violations))
+	for _, violation := range report.Violations {
+		var asset template.Asset
+		if readsAsset {
+			var err error
+			asset, err = template.ParseAsset(violation.ViolatedAsset.Asset)
+			if err != nil {
+				return nil, fmt.Errorf("asset %s of policy %s: template.ParseAsset(): %v", violation.AssetID, violation.PolicyID, err)
+			}
+		}
+		violations = append(violations, tableViolation{violation: violation, asset: asset})
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		for _, key := range sortKeys {
+			if c := compareColumn(key, violations[i], violations[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	rows := [][]string{columns}
+	for _, v := range violations {
+		row := make([]string, 0, len(columns))
+		for _, column := range columns {
+			row = append(row, columnValues[column](v.violation, v.asset))
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// WriteTable writes the violations of the report as CSV, or as TSV when the
+// delimiter is a tab. CSV cells are quoted as needed, while TSV has no quoting:
+// the tabs and line breaks of its cells are replaced by spaces. Cells
+// spreadsheets would read as formulas are escaped.
+func WriteTable(w io.Writer, report template.IACValidationReport, opts TableOptions, delimiter rune) error {
+	rows, err := ToTable(report, opts)
+	if err != nil {
+		return fmt.Errorf("ToTable: %v", err)
+	}
+	for _, row := range rows[1:] {
+		for i, cell := range row {
+			row[i] = escapeFormula(cell)
+		}
+	}
+
+	if delimiter == '\t' {
+		for _, row := range rows {
+			for i, cell := range row {
+				row[i] = tsvReplacer.Replace(cell)
+			}
+			if _, err := io.WriteString(w, strings.Join(row, "\t")+"\n"); err != nil {
+				return fmt.Errorf("io.WriteString: %v", err)
+			}
+		}
+		return nil
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("csv.WriteAll: %v", err)
+	}
+
+	return nil
+}
+
+var tsvReplacer = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+// escapeFormula prefixes the cells starting like a formula with a quote, so
+// report fields such as descriptions or next steps are not executed when the
+// export is opened in a spreadsheet.
+func escapeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+
+	return cell
+}
+
+func compareColumn(column string, a, b tableViolation) int {
+	if column == COLUMN_SEVERITY {
+		return template.ParseSeverity(b.violation.Severity).Rank() - template.ParseSeverity(a.violation.Severity).Rank()
+	}
+
+	return strings.Compare(columnValues[column](a.violation, a.asset), columnValues[column](b.violation, b.asset))
+}
@@ -0,0 +1,192 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package converter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	template "github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+var tabularReport = template.IACValidationReport{
+	Violations: []template.Violation{
+		{AssetID: "asset1", PolicyID: "policy2", Severity: "LOW", ViolatedAsset: template.AssetDetails{AssetType: "type2"}},
+		{AssetID: "asset2", PolicyID: "policy1", Severity: "CRITICAL", ViolatedAsset: template.AssetDetails{AssetType: "type1"}},
+		{
+			AssetID:        "asset3",
+			PolicyID:       "policy3",
+			Severity:       "HIGH",
+			ViolatedAsset:  template.AssetDetails{AssetType: "type1"},
+			ViolatedPolicy: template.PolicyDetails{ComplianceStandards: []string{"CIS 2.0 1.1", "NIST 800-53 AC-2"}},
+		},
+	},
+}
+
+func TestToTable(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         TableOptions
+		expectedRows [][]string
+		wantErr      bool
+	}{
+		{
+			name: "SelectedColumns_ReportOrder",
+			opts: TableOptions{Columns: []string{COLUMN_ASSET_ID, COLUMN_SEVERITY}},
+			expectedRows: [][]string{
+				{"assetId", "severity"},
+				{"asset1", "LOW"},
+				{"asset2", "CRITICAL"},
+				{"asset3", "HIGH"},
+			},
+		},
+		{
+			name: "SortBySeverity_MostSevereFirst",
+			opts: TableOptions{Columns: []string{COLUMN_ASSET_ID, COLUMN_SEVERITY}, SortBy: []string{COLUMN_SEVERITY}},
+			expectedRows: [][]string{
+				{"assetId", "severity"},
+				{"asset2", "CRITICAL"},
+				{"asset3", "HIGH"},
+				{"asset1", "LOW"},
+			},
+		},
+		{
+			name: "GroupByAssetType_SortedWithinGroup",
+			opts: TableOptions{
+				Columns: []string{COLUMN_ASSET_TYPE, COLUMN_POLICY_ID},
+				GroupBy: COLUMN_ASSET_TYPE,
+				SortBy:  []string{COLUMN_POLICY_ID},
+			},
+			expectedRows: [][]string{
+				{"assetType", "policyId"},
+				{"type1", "policy1"},
+				{"type1", "policy3"},
+				{"type2", "policy2"},
+			},
+		},
+		{
+			name:    "UnknownColumn_Failure",
+			opts:    TableOptions{Columns: []string{"owner"}},
+			wantErr: true,
+		},
+		{
+			name:    "UnknownSortColumn_Failure",
+			opts:    TableOptions{SortBy: []string{"owner"}},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows, err := ToTable(tabularReport, test.opts)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if diff := cmp.Diff(test.expectedRows, rows); diff != "" {
+				t.Errorf("Expected rows (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	opts := TableOptions{Columns: []string{COLUMN_ASSET_ID, COLUMN_COMPLIANCE_STANDARDS}}
+	report := template.IACValidationReport{Violations: tabularReport.Violations[2:]}
+
+	tests := []struct {
+		name      string
+		delimiter rune
+		expected  string
+	}{
+		{
+			name:      "CSV_MultiValuedFieldQuoted",
+			delimiter: ',',
+			expected:  "assetId,complianceStandards\nasset3,\"CIS 2.0 1.1, NIST 800-53 AC-2\"\n",
+		},
+		{
+			name:      "TSV_MultiValuedFieldNotQuoted",
+			delimiter: '\t',
+			expected:  "assetId\tcomplianceStandards\nasset3\tCIS 2.0 1.1, NIST 800-53 AC-2\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := WriteTable(&b, report, opts, test.delimiter); err != nil {
+				t.Fatalf("WriteTable: %v", err)
+			}
+
+			if diff := cmp.Diff(test.expected, b.String()); diff != "" {
+				t.Errorf("Expected output (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestWriteTable_EscapesFormulas(t *testing.T) {
+	report := template.IACValidationReport{Violations: []template.Violation{
+		{AssetID: "=HYPERLINK(\"http://example.com\")", PolicyID: "+1", NextSteps: "-2"},
+		{AssetID: "@SUM(A1)", PolicyID: "policy-1", NextSteps: "Set a-b=c."},
+	}}
+	opts := TableOptions{Columns: []string{COLUMN_ASSET_ID, COLUMN_POLICY_ID, COLUMN_NEXT_STEPS}}
+
+	var b bytes.Buffer
+	if err := WriteTable(&b, report, opts, ','); err != nil {
+		t.Fatalf("WriteTable: %v", err)
+	}
+
+	expected := "assetId,policyId,nextSteps\n" +
+		"\"'=HYPERLINK(\"\"http://example.com\"\")\",'+1,'-2\n" +
+		"'@SUM(A1),policy-1,Set a-b=c.\n"
+	if diff := cmp.Diff(expected, b.String()); diff != "" {
+		t.Errorf("Expected output (+got, -want): %v", diff)
+	}
+}
+
+func TestWriteTable_TSVReplacesSeparators(t *testing.T) {
+	report := template.IACValidationReport{Violations: []template.Violation{
+		{AssetID: "asset1", NextSteps: "Set \"a\"\tthen\nb."},
+	}}
+	opts := TableOptions{Columns: []string{COLUMN_ASSET_ID, COLUMN_NEXT_STEPS}}
+
+	var b bytes.Buffer
+	if err := WriteTable(&b, report, opts, '\t'); err != nil {
+		t.Fatalf("WriteTable: %v", err)
+	}
+
+	expected := "assetId\tnextSteps\nasset1\tSet \"a\" then b.\n"
+	if diff := cmp.Diff(expected, b.String()); diff != "" {
+		t.Errorf("Expected output (+got, -want): %v", diff)
+	}
+}
+
+func TestToTable_MalformedAsset(t *testing.T) {
+	report := template.IACValidationReport{Violations: []template.Violation{
+		{AssetID: "asset1", PolicyID: "policy1", ViolatedAsset: template.AssetDetails{Asset: "Asset 1"}},
+	}}
+
+	if _, err := ToTable(report, TableOptions{Columns: []string{COLUMN_ASSET_ID, COLUMN_PROJECT}}); err == nil {
+		t.Errorf("Expected error for a project column of a malformed asset, got nil")
+	}
+	if _, err := ToTable(report, TableOptions{Columns: []string{COLUMN_ASSET_ID}}); err != nil {
+		t.Errorf("Expected the asset not to be decoded without asset columns, got: %v", err)
+	}
+}
@@ -17,7 +17,7 @@
 package converter
 
 import (
-	template "github.com/pritiprajapati314/IACPlugin2024/SARIFConverter/template"
+	template "github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
 )
 
 var IACValidationValidReport = template.IACValidationReport{
@@ -87,8 +87,14 @@ var IACValidSarifOutput = template.SarifOutput{
 					InformationURI: IAC_TOOL_DOCUMENTATION_LINK,
 					Rules: []template.Rule{
 						{
-							ID:              "P1",
-							FullDescription: template.FullDescription{Text: "High-level violation message"},
+							ID:               "P1",
+							Name:             "P1",
+							ShortDescription: &template.MultiformatMessage{Text: "High-level violation message"},
+							FullDescription:  template.FullDescription{Text: "High-level violation message"},
+							Help: &template.MultiformatMessage{
+								Text:     "Description: High-level violation message\nNext steps: Next steps 1\nCompliance standards: Standard 1",
+								Markdown: "**Description**: High-level violation message\n\n**Next steps**: Next steps 1\n\n**Compliance standards**: Standard 1",
+							},
 							Properties: template.RuleProperties{
 								Severity:            "HIGH",
 								PolicyType:          "Type 1",
@@ -115,6 +121,7 @@ var IACValidSarifOutput = template.SarifOutput{
 						},
 					},
 					Properties: template.ResultProperties{
+						Severity:  "HIGH",
 						AssetID:   "Asset 1",
 						Asset:     "Asset 1",
 						AssetType: "Type 1",
@@ -14,62 +14,273 @@
  limitations under the License.
 */
 
-// Package main converts IaC validation report in JSON to SARIF format.
+// Package main converts IaC validation report in JSON to SARIF format, or to
+// CSV, TSV, Checkstyle, OpenVEX and the GitLab report formats.
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
 	"flag"
 	"fmt"
 	"os"
-	"encoding/json"
+	"strings"
+	"time"
 
-	"github.com/pritiprajapati314/IACPlugin2024/SARIFConverter/converter"
-	"github.com/pritiprajapati314/IACPlugin2024/SARIFConverter/template"
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/converter"
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+	"github.com/mikelaramie/IACPlugin2024/posture"
 )
 
 var (
 	inputFilePath  = flag.String("filePath", "", "path of the input file")
 	outputFilePath = flag.String("output", "output.json", "path of the output file")
+	strict         = flag.Bool("strict", false, "fail on empty or malformed reports")
+	unknownSev     = flag.String("unknownSeverity", template.UnknownSeverityFail, "handling of unknown severities: fail, ignore or treat-as-<severity>")
+	format         = flag.String("format", "sarif", "output format: sarif, csv, tsv, checkstyle, openvex, gitlab-sast, gitlab-codequality, summary or score")
+	columns        = flag.String("columns", "", "comma separated columns of the csv and tsv formats, all by default")
+	groupBy        = flag.String("groupBy", "", "column grouping the rows of the csv and tsv formats")
+	sortBy         = flag.String("sortBy", "", "comma separated columns sorting the rows of the csv and tsv formats")
+	fileMapping    = flag.String("fileMapping", "", "path of the json file mapping asset IDs to source files, for the checkstyle and gitlab formats and the path patterns of -owners")
+	ownersFile     = flag.String("owners", "", "path of the CODEOWNERS-style file mapping assets to teams, for the sarif and summary formats")
+	waiversFile    = flag.String("waivers", "", "path of the json file listing accepted risks, for the openvex format")
+	author         = flag.String("author", "", "author of the openvex document, required by the openvex format")
+	splitRuns      = flag.Bool("splitRuns", false, "produce one sarif run per posture deployment")
+	catalogPath    = flag.String("catalog", "", "path of the policy library, a json or yaml file or a directory of them, enriching the sarif format")
+	include        = flag.String("include", "", "comma separated field=pattern terms the converted violations must all match")
+	exclude        = flag.String("exclude", "", "comma separated field=pattern terms excluding the violations matching any of them")
+	helpURIs       = flag.String("helpUriTemplates", "", "path of the json file mapping constraint types to help URI templates, for the sarif format")
+	riskScore      = flag.Bool("riskScore", false, "add the risk score of the violations to the sarif run properties")
+	scoreWeights   = flag.String("scoreWeights", "", "path of the json file weighing severities, policies and asset types in the risk score, implies -riskScore")
 )
 
 func main() {
 	flag.Parse()
 
-	iacReport, err := readAndParseIACScanReport(inputFilePath)
+	severityPolicy, err := template.ParseUnknownSeverityPolicy(*unknownSev)
 	if err != nil {
-		fmt.Printf("readAndParseIACScanReport: %v", err)
+		fmt.Printf("template.ParseUnknownSeverityPolicy: %v", err)
 		os.Exit(1)
 	}
 
-	sarifReport, err := converter.FromIACScanReport(iacReport.Response.IacValidationReport)
+	filter, err := template.ParseViolationFilter(*include, *exclude)
 	if err != nil {
-		fmt.Printf("sarif.FromIACScanReport: %v", err)
+		fmt.Printf("template.ParseViolationFilter: %v", err)
 		os.Exit(1)
 	}
 
-	if err := writeSarifReport(sarifReport, outputFilePath); err != nil {
-		fmt.Printf("writeSarifReport(): %v", err)
+	iacReport, err := readAndParseIACScanReport(inputFilePath, *strict, severityPolicy)
+	if err != nil {
+		fmt.Printf("readAndParseIACScanReport: %v", err)
 		os.Exit(1)
 	}
-}
+	iacReport.IacValidationReport.Violations, err = filter.Apply(iacReport.IacValidationReport.Violations)
+	if err != nil {
+		fmt.Printf("filter.Apply: %v", err)
+		os.Exit(1)
+	}
+
+	switch *format {
+	case "sarif":
+		helpURITemplates, err := readHelpURITemplates(*helpURIs)
+		if err != nil {
+			fmt.Printf("readHelpURITemplates(): %v", err)
+			os.Exit(1)
+		}
+
+		var catalog template.Catalog
+		if *catalogPath != "" {
+			catalog, err = posture.LoadCatalog(*catalogPath)
+			if err != nil {
+				fmt.Printf("posture.LoadCatalog(): %v", err)
+				os.Exit(1)
+			}
+		}
+
+		ownership, sourceFiles, err := readOwnership(*ownersFile, *fileMapping)
+		if err != nil {
+			fmt.Printf("readOwnership(): %v", err)
+			os.Exit(1)
+		}
+
+		opts := converter.Options{
+			SplitByPostureDeployment: *splitRuns,
+			HelpURITemplates:         helpURITemplates,
+			Catalog:                  catalog,
+			Ownership:                ownership,
+			SourceFiles:              sourceFiles,
+		}
+		if *riskScore || *scoreWeights != "" {
+			weights, err := readScoreWeights(*scoreWeights)
+			if err != nil {
+				fmt.Printf("readScoreWeights(): %v", err)
+				os.Exit(1)
+			}
+			opts.ScoreWeights = &weights
+		}
+
+		sarifReport, err := converter.FromIACScanReportWithOptions(iacReport.IacValidationReport, opts)
+		if err != nil {
+			fmt.Printf("sarif.FromIACScanReportWithOptions: %v", err)
+			os.Exit(1)
+		}
+
+		for _, run := range sarifReport.Runs {
+			for _, invocation := range run.Invocations {
+				for _, notification := range invocation.ToolExecutionNotifications {
+					fmt.Fprintf(os.Stderr, "%s: %s\n", notification.Level, notification.Message.Text)
+				}
+			}
+		}
+
+		if err := writeJSONReport(sarifReport, outputFilePath); err != nil {
+			fmt.Printf("writeJSONReport(): %v", err)
+			os.Exit(1)
+		}
+	case "checkstyle":
+		mapping, err := template.LoadFileMapping(*fileMapping)
+		if err != nil {
+			fmt.Printf("template.LoadFileMapping(): %v", err)
+			os.Exit(1)
+		}
+
+		checkstyleReport, err := converter.ToCheckstyle(iacReport.IacValidationReport, mapping)
+		if err != nil {
+			fmt.Printf("converter.ToCheckstyle: %v", err)
+			os.Exit(1)
+		}
+
+		if err := writeXMLReport(checkstyleReport, outputFilePath); err != nil {
+			fmt.Printf("writeXMLReport(): %v", err)
+			os.Exit(1)
+		}
+	case "openvex":
+		waivers, err := readWaivers(*waiversFile)
+		if err != nil {
+			fmt.Printf("readWaivers(): %v", err)
+			os.Exit(1)
+		}
+
+		vexDocument, err := converter.ToOpenVEX(iacReport.IacValidationReport, waivers, *author, time.Now())
+		if err != nil {
+			fmt.Printf("converter.ToOpenVEX: %v", err)
+			os.Exit(1)
+		}
+
+		if err := writeJSONReport(vexDocument, outputFilePath); err != nil {
+			fmt.Printf("writeJSONReport(): %v", err)
+			os.Exit(1)
+		}
+	case "gitlab-sast":
+		mapping, err := template.LoadFileMapping(*fileMapping)
+		if err != nil {
+			fmt.Printf("template.LoadFileMapping(): %v", err)
+			os.Exit(1)
+		}
+
+		sastReport, err := converter.ToGitLabSAST(iacReport, time.Now(), mapping)
+		if err != nil {
+			fmt.Printf("converter.ToGitLabSAST: %v", err)
+			os.Exit(1)
+		}
+
+		if err := writeJSONReport(sastReport, outputFilePath); err != nil {
+			fmt.Printf("writeJSONReport(): %v", err)
+			os.Exit(1)
+		}
+	case "gitlab-codequality":
+		mapping, err := template.LoadFileMapping(*fileMapping)
+		if err != nil {
+			fmt.Printf("template.LoadFileMapping(): %v", err)
+			os.Exit(1)
+		}
+
+		issues, err := converter.ToGitLabCodeQuality(iacReport.IacValidationReport, mapping)
+		if err != nil {
+			fmt.Printf("converter.ToGitLabCodeQuality: %v", err)
+			os.Exit(1)
+		}
 
+		if err := writeJSONReport(issues, outputFilePath); err != nil {
+			fmt.Printf("writeJSONReport(): %v", err)
+			os.Exit(1)
+		}
+	case "summary":
+		ownership, sourceFiles, err := readOwnership(*ownersFile, *fileMapping)
+		if err != nil {
+			fmt.Printf("readOwnership(): %v", err)
+			os.Exit(1)
+		}
 
-func readAndParseIACScanReport(filePath *string) (template.IACReportTemplate, error) {
+		summary := converter.ToOwnerSummary(iacReport.IacValidationReport, ownership, sourceFiles)
+		if err := writeJSONReport(summary, outputFilePath); err != nil {
+			fmt.Printf("writeJSONReport(): %v", err)
+			os.Exit(1)
+		}
+	case "score":
+		weights, err := readScoreWeights(*scoreWeights)
+		if err != nil {
+			fmt.Printf("readScoreWeights(): %v", err)
+			os.Exit(1)
+		}
+
+		score := template.ComputeScore(iacReport.IacValidationReport.Violations, weights)
+		if err := writeJSONReport(score, outputFilePath); err != nil {
+			fmt.Printf("writeJSONReport(): %v", err)
+			os.Exit(1)
+		}
+	case "csv", "tsv":
+		delimiter := ','
+		if *format == "tsv" {
+			delimiter = '\t'
+		}
+
+		opts := converter.TableOptions{
+			Columns: splitList(*columns),
+			GroupBy: *groupBy,
+			SortBy:  splitList(*sortBy),
+		}
+
+		if err := writeTabularReport(iacReport.IacValidationReport, opts, delimiter, outputFilePath); err != nil {
+			fmt.Printf("writeTabularReport(): %v", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf("unsupported output format: %v", *format)
+		os.Exit(1)
+	}
+}
+
+func readAndParseIACScanReport(filePath *string, strict bool, policy template.UnknownSeverityPolicy) (template.Responses, error) {
 	data, err := os.ReadFile(*filePath)
 	if err != nil {
-		return template.IACReportTemplate{}, fmt.Errorf("os.ReadFile(%s): %v", *filePath, err)
+		return template.Responses{}, fmt.Errorf("os.ReadFile(%s): %v", *filePath, err)
 	}
 
-	var iacReport template.IACReportTemplate
-	if err = json.Unmarshal(data, &iacReport); err != nil {
-		return template.IACReportTemplate{}, fmt.Errorf("json.Unmarshal(): %v", err)
+	var iacReport template.Responses
+	if strict {
+		iacReport, err = template.ParseReportStrict(data, policy)
+		if err != nil {
+			return template.Responses{}, fmt.Errorf("template.ParseReportStrict(): %v", err)
+		}
+	} else {
+		iacReport, err = template.ParseReport(data)
+		if err != nil {
+			return template.Responses{}, fmt.Errorf("template.ParseReport(): %v", err)
+		}
+	}
+
+	iacReport.IacValidationReport, err = template.ApplyUnknownSeverityPolicy(iacReport.IacValidationReport, policy)
+	if err != nil {
+		return template.Responses{}, fmt.Errorf("template.ApplyUnknownSeverityPolicy(): %v", err)
 	}
 
 	return iacReport, nil
 }
 
-func writeSarifReport(sarifReport template.SarifOutput, outputFilePath *string) error {
-	sarifJSON, err := json.MarshalIndent(sarifReport, "", "  ")
+func writeJSONReport(report interface{}, outputFilePath *string) error {
+	reportJSON, err := json.MarshalIndent(report, "", "  ")
 	if err != nil {
 		return fmt.Errorf("json.MarshalIndent: %v", err)
 	}
@@ -80,10 +291,118 @@ func writeSarifReport(sarifReport template.SarifOutput, outputFilePath *string)
 	}
 	defer outputJSON.Close()
 
-	_, err = outputJSON.Write(sarifJSON)
+	_, err = outputJSON.Write(reportJSON)
 	if err != nil {
 		return fmt.Errorf("outputJSON.Write: %v", err)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func writeXMLReport(report interface{}, outputFilePath *string) error {
+	reportXML, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("xml.MarshalIndent: %v", err)
+	}
+
+	if err := os.WriteFile(*outputFilePath, append([]byte(xml.Header), reportXML...), 0o644); err != nil {
+		return fmt.Errorf("os.WriteFile: %v", err)
+	}
+
+	return nil
+}
+
+func readHelpURITemplates(filePath string) (map[string]string, error) {
+	if filePath == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile(%s): %v", filePath, err)
+	}
+
+	var templates map[string]string
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(): %v", err)
+	}
+
+	return templates, nil
+}
+
+func readOwnership(ownersPath, fileMappingPath string) (template.Ownership, map[string]string, error) {
+	if ownersPath == "" {
+		return template.Ownership{}, nil, nil
+	}
+
+	ownership, err := template.LoadOwnership(ownersPath)
+	if err != nil {
+		return template.Ownership{}, nil, fmt.Errorf("template.LoadOwnership(): %v", err)
+	}
+
+	sourceFiles, err := template.LoadFileMapping(fileMappingPath)
+	if err != nil {
+		return template.Ownership{}, nil, fmt.Errorf("template.LoadFileMapping(): %v", err)
+	}
+
+	return ownership, sourceFiles, nil
+}
+
+func readScoreWeights(filePath string) (template.ScoreWeights, error) {
+	if filePath == "" {
+		return template.DefaultScoreWeights, nil
+	}
+
+	weights, err := template.LoadScoreWeights(filePath)
+	if err != nil {
+		return template.ScoreWeights{}, fmt.Errorf("template.LoadScoreWeights(): %v", err)
+	}
+
+	return weights, nil
+}
+
+func readWaivers(filePath string) ([]template.Waiver, error) {
+	if filePath == "" {
+		return nil, fmt.Errorf("-waivers is required by the openvex format")
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile(%s): %v", filePath, err)
+	}
+
+	var waiverFile template.WaiverFile
+	if err := json.Unmarshal(data, &waiverFile); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(): %v", err)
+	}
+
+	return waiverFile.Waivers, nil
+}
+
+func writeTabularReport(report template.IACValidationReport, opts converter.TableOptions, delimiter rune, outputFilePath *string) error {
+	// The table is built before the output file is created, so invalid
+	// columns leave no truncated file behind.
+	var output bytes.Buffer
+	if err := converter.WriteTable(&output, report, opts, delimiter); err != nil {
+		return fmt.Errorf("converter.WriteTable: %v", err)
+	}
+
+	if err := os.WriteFile(*outputFilePath, output.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("os.WriteFile: %v", err)
+	}
+
+	return nil
+}
+
+func splitList(list string) []string {
+	if list == "" {
+		return nil
+	}
+
+	items := strings.Split(list, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+
+	return items
+}
@@ -0,0 +1,179 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Asset is the Cloud Asset Inventory resource serialized in
+// AssetDetails.Asset, with its name, project, location and labels extracted.
+type Asset struct {
+	Name      string            `json:"name,omitempty"`
+	AssetType string            `json:"assetType,omitempty"`
+	Project   string            `json:"project,omitempty"`
+	Location  string            `json:"location,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	// Data is the resource payload, as defined by the API of the asset type.
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// caiAsset is the subset of a Cloud Asset Inventory asset the parser reads.
+type caiAsset struct {
+	Name      string   `json:"name"`
+	AssetType string   `json:"assetType"`
+	Ancestors []string `json:"ancestors"`
+	Resource  struct {
+		Parent   string                 `json:"parent"`
+		Location string                 `json:"location"`
+		Data     map[string]interface{} `json:"data"`
+	} `json:"resource"`
+}
+
+// ParseAsset decodes the serialized asset of a violation. The project and
+// location come from the resource when it has them and are otherwise derived
+// from the resource name, parent and ancestors; zones are kept as is.
+func ParseAsset(serialized string) (Asset, error) {
+	if strings.TrimSpace(serialized) == "" {
+		return Asset{}, nil
+	}
+
+	var cai caiAsset
+	if err := json.Unmarshal([]byte(serialized), &cai); err != nil {
+		return Asset{}, fmt.Errorf("json.Unmarshal(): %v", err)
+	}
+
+	asset := Asset{
+		Name:      cai.Name,
+		AssetType: cai.AssetType,
+		Data:      cai.Resource.Data,
+		Project:   stringField(cai.Resource.Data, "project"),
+		Location:  cai.Resource.Location,
+	}
+
+	if asset.Project == "" {
+		for _, name := range append([]string{cai.Name, cai.Resource.Parent}, cai.Ancestors...) {
+			if project := nameSegment(name, "projects"); project != "" {
+				asset.Project = project
+				break
+			}
+		}
+	}
+
+	for _, field := range []string{"location", "region", "zone"} {
+		if asset.Location != "" {
+			break
+		}
+		asset.Location = path.Base(stringField(cai.Resource.Data, field))
+		if asset.Location == "." {
+			asset.Location = ""
+		}
+	}
+	for _, collection := range []string{"locations", "regions", "zones"} {
+		if asset.Location != "" {
+			break
+		}
+		asset.Location = nameSegment(cai.Name, collection)
+	}
+
+	if labels, ok := cai.Resource.Data["labels"].(map[string]interface{}); ok {
+		asset.Labels = make(map[string]string, len(labels))
+		for key, value := range labels {
+			asset.Labels[key] = fmt.Sprint(value)
+		}
+	}
+
+	return asset, nil
+}
+
+func stringField(data map[string]interface{}, field string) string {
+	value, _ := data[field].(string)
+	return value
+}
+
+// nameSegment returns the identifier following the collection in a resource
+// name, e.g. p1 for projects in //compute.googleapis.com/projects/p1/zones/z.
+func nameSegment(name, collection string) string {
+	segments := strings.Split(name, "/")
+	for i := 0; i < len(segments)-1; i++ {
+		if segments[i] == collection && segments[i+1] != "" && segments[i+1] != "_" {
+			return segments[i+1]
+		}
+	}
+
+	return ""
+}
+
+// AssetScope selects the violations by the project, location and labels of
+// their asset. Empty fields select every asset.
+type AssetScope struct {
+	Project  string
+	Location string
+	Labels   map[string]string
+}
+
+// Filter returns the filter including the assets within the scope, so scopes
+// and -include terms share one matcher.
+func (s AssetScope) Filter() ViolationFilter {
+	filter := ViolationFilter{}
+	if s.Project != "" {
+		filter.Include = append(filter.Include, FilterTerm{Field: FILTER_PROJECT, Pattern: escapeGlob(s.Project)})
+	}
+	if s.Location != "" {
+		filter.Include = append(filter.Include, FilterTerm{Field: FILTER_LOCATION, Pattern: escapeGlob(s.Location)})
+	}
+
+	keys := make([]string, 0, len(s.Labels))
+	for key := range s.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		filter.Include = append(filter.Include, FilterTerm{Field: FILTER_LABEL_PREFIX + key, Pattern: escapeGlob(s.Labels[key])})
+	}
+
+	return filter
+}
+
+// escapeGlob quotes the MatchGlob metacharacters of a literal value.
+func escapeGlob(value string) string {
+	return globReplacer.Replace(value)
+}
+
+var globReplacer = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`)
+
+// ParseLabelSelector parses comma separated key=value labels.
+func ParseLabelSelector(selector string) (map[string]string, error) {
+	if strings.TrimSpace(selector) == "" {
+		return nil, nil
+	}
+
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(selector, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid label %q, expected key=value", pair)
+		}
+		labels[key] = value
+	}
+
+	return labels, nil
+}
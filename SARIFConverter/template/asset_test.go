@@ -0,0 +1,147 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+const bucketAsset = `{
+	"name": "//storage.googleapis.com/prod-logs",
+	"assetType": "storage.googleapis.com/Bucket",
+	"ancestors": ["projects/1234", "folders/42"],
+	"resource": {
+		"parent": "//cloudresourcemanager.googleapis.com/projects/prod-app",
+		"data": {"name": "prod-logs", "location": "US", "labels": {"env": "prod", "team": "storage"}}
+	}
+}`
+
+const instanceAsset = `{
+	"name": "//compute.googleapis.com/projects/sandbox-app/zones/europe-west1-b/instances/vm-1",
+	"assetType": "compute.googleapis.com/Instance",
+	"resource": {"data": {"zone": "projects/sandbox-app/zones/europe-west1-b", "labels": {"env": "sandbox"}}}
+}`
+
+func TestParseAsset(t *testing.T) {
+	tests := []struct {
+		name          string
+		serialized    string
+		expectedAsset Asset
+		wantErr       bool
+	}{
+		{
+			name:       "Bucket_ProjectFromParent",
+			serialized: bucketAsset,
+			expectedAsset: Asset{
+				Name:      "//storage.googleapis.com/prod-logs",
+				AssetType: "storage.googleapis.com/Bucket",
+				Project:   "prod-app",
+				Location:  "US",
+				Labels:    map[string]string{"env": "prod", "team": "storage"},
+			},
+		},
+		{
+			name:       "Instance_ProjectAndZoneFromName",
+			serialized: instanceAsset,
+			expectedAsset: Asset{
+				Name:      "//compute.googleapis.com/projects/sandbox-app/zones/europe-west1-b/instances/vm-1",
+				AssetType: "compute.googleapis.com/Instance",
+				Project:   "sandbox-app",
+				Location:  "europe-west1-b",
+				Labels:    map[string]string{"env": "sandbox"},
+			},
+		},
+		{
+			name:          "Empty_Succeeds",
+			serialized:    "",
+			expectedAsset: Asset{},
+		},
+		{
+			name:       "NotJSON_Failure",
+			serialized: "Asset 1",
+			wantErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			asset, err := ParseAsset(test.serialized)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if diff := cmp.Diff(test.expectedAsset, asset, cmpopts.IgnoreFields(Asset{}, "Data")); diff != "" {
+				t.Errorf("Expected asset (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestAssetScope_Filter(t *testing.T) {
+	violations := []Violation{
+		{AssetID: "bucket", ViolatedAsset: AssetDetails{Asset: bucketAsset}},
+		{AssetID: "vm", ViolatedAsset: AssetDetails{Asset: instanceAsset}},
+		{AssetID: "opaque", ViolatedAsset: AssetDetails{}},
+	}
+
+	tests := []struct {
+		name           string
+		scope          AssetScope
+		expectedAssets []string
+	}{
+		{name: "EmptyScope", scope: AssetScope{}, expectedAssets: []string{"bucket", "vm", "opaque"}},
+		{name: "Project", scope: AssetScope{Project: "prod-app"}, expectedAssets: []string{"bucket"}},
+		{name: "Location", scope: AssetScope{Location: "europe-west1-b"}, expectedAssets: []string{"vm"}},
+		{name: "Label", scope: AssetScope{Labels: map[string]string{"env": "sandbox"}}, expectedAssets: []string{"vm"}},
+		{name: "LiteralProject", scope: AssetScope{Project: "prod-*"}, expectedAssets: []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filtered, err := test.scope.Filter().Apply(violations)
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+
+			assets := []string{}
+			for _, violation := range filtered {
+				assets = append(assets, violation.AssetID)
+			}
+
+			if diff := cmp.Diff(test.expectedAssets, assets); diff != "" {
+				t.Errorf("Expected assets (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestParseLabelSelector(t *testing.T) {
+	labels, err := ParseLabelSelector("env=prod, team=storage")
+	if err != nil {
+		t.Fatalf("ParseLabelSelector: %v", err)
+	}
+	if diff := cmp.Diff(map[string]string{"env": "prod", "team": "storage"}, labels); diff != "" {
+		t.Errorf("Expected labels (+got, -want): %v", diff)
+	}
+
+	if _, err := ParseLabelSelector("env"); err == nil {
+		t.Errorf("Expected an error for a label without value")
+	}
+}
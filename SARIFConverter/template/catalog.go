@@ -0,0 +1,87 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Catalog is a local policy library enriching the policies of a report with
+// what SCC does not return.
+type Catalog struct {
+	Policies []CatalogPolicy `yaml:"policies" json:"policies"`
+}
+
+// CatalogPolicy enriches the violations of a policy, matched by PolicyID or
+// else by Constraint.
+type CatalogPolicy struct {
+	PolicyID   string `yaml:"policyId" json:"policyId"`
+	Constraint string `yaml:"constraint" json:"constraint"`
+	Owner      string `yaml:"owner" json:"owner"`
+	RunbookURL string `yaml:"runbookUrl" json:"runbookUrl"`
+	// RiskRating overrides the severity reported by SCC.
+	RiskRating string   `yaml:"riskRating" json:"riskRating"`
+	Tags       []string `yaml:"tags" json:"tags"`
+}
+
+// Lookup returns the catalog entry of the violated policy.
+func (c Catalog) Lookup(violation Violation) (CatalogPolicy, bool) {
+	for _, policy := range c.Policies {
+		if policy.PolicyID != "" && policy.PolicyID == violation.PolicyID {
+			return policy, true
+		}
+	}
+
+	for _, policy := range c.Policies {
+		if policy.Constraint != "" && policy.Constraint == violation.ViolatedPolicy.Constraint {
+			return policy, true
+		}
+	}
+
+	return CatalogPolicy{}, false
+}
+
+// ParseCatalog parses a JSON or YAML policy library. Posture files are read
+// by posture.LoadCatalog.
+func ParseCatalog(data []byte) (Catalog, error) {
+	var catalog Catalog
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return Catalog{}, fmt.Errorf("yaml.Unmarshal(): %v", err)
+	}
+
+	if err := catalog.Validate(); err != nil {
+		return Catalog{}, err
+	}
+
+	return catalog, nil
+}
+
+// Validate checks that the policies have a key and a known risk rating.
+func (c Catalog) Validate() error {
+	for i, policy := range c.Policies {
+		if policy.PolicyID == "" && policy.Constraint == "" {
+			return fmt.Errorf("policy %d: policyId or constraint is required", i)
+		}
+		if policy.RiskRating != "" && !ParseSeverity(policy.RiskRating).IsKnown() {
+			return fmt.Errorf("policy %d: unknown risk rating %q", i, policy.RiskRating)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCatalog(t *testing.T) {
+	tests := []struct {
+		name            string
+		data            string
+		expectedCatalog Catalog
+		wantErr         bool
+	}{
+		{
+			name: "JSONLibrary_Succeeds",
+			data: `{"policies": [{"policyId": "P1", "owner": "team-a", "runbookUrl": "https://runbooks/p1", "riskRating": "LOW", "tags": ["pci"]}]}`,
+			expectedCatalog: Catalog{Policies: []CatalogPolicy{
+				{PolicyID: "P1", Owner: "team-a", RunbookURL: "https://runbooks/p1", RiskRating: "LOW", Tags: []string{"pci"}},
+			}},
+		},
+		{
+			name:    "MissingKey_Failure",
+			data:    `{"policies": [{"owner": "team-a"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "UnknownRiskRating_Failure",
+			data:    `{"policies": [{"policyId": "P1", "riskRating": "SEVERE"}]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			catalog, err := ParseCatalog([]byte(test.data))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if diff := cmp.Diff(test.expectedCatalog, catalog); diff != "" {
+				t.Errorf("Expected catalog (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestCatalogLookup(t *testing.T) {
+	catalog := Catalog{Policies: []CatalogPolicy{
+		{Constraint: "storage.uniformBucketLevelAccess", Owner: "by-constraint"},
+		{PolicyID: "P1", Owner: "by-policy"},
+	}}
+
+	tests := []struct {
+		name          string
+		violation     Violation
+		expectedOwner string
+		expectedFound bool
+	}{
+		{
+			name:          "PolicyIDPreferred",
+			violation:     Violation{PolicyID: "P1", ViolatedPolicy: PolicyDetails{Constraint: "storage.uniformBucketLevelAccess"}},
+			expectedOwner: "by-policy",
+			expectedFound: true,
+		},
+		{
+			name:          "ConstraintFallback",
+			violation:     Violation{PolicyID: "P2", ViolatedPolicy: PolicyDetails{Constraint: "storage.uniformBucketLevelAccess"}},
+			expectedOwner: "by-constraint",
+			expectedFound: true,
+		},
+		{
+			name:      "NotFound",
+			violation: Violation{PolicyID: "P3"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy, found := catalog.Lookup(test.violation)
+			if found != test.expectedFound || policy.Owner != test.expectedOwner {
+				t.Errorf("Expected owner %q (found: %v), got: %q (found: %v)", test.expectedOwner, test.expectedFound, policy.Owner, found)
+			}
+		})
+	}
+}
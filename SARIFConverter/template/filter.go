@@ -0,0 +1,200 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	FILTER_ASSET_TYPE   = "assetType"
+	FILTER_PROJECT      = "project"
+	FILTER_REGION       = "region"
+	FILTER_LOCATION     = "location"
+	FILTER_POLICY_ID    = "policyId"
+	FILTER_LABEL_PREFIX = "label."
+)
+
+// ViolationFilter scopes the violations of a report. A violation is kept
+// when it matches every include term and none of the exclude terms.
+type ViolationFilter struct {
+	Include []FilterTerm
+	Exclude []FilterTerm
+}
+
+// FilterTerm matches the violations whose field matches the MatchGlob glob.
+// The field is assetType, project, region (or location), policyId or a label
+// key prefixed with "label.".
+type FilterTerm struct {
+	Field   string
+	Pattern string
+}
+
+// ParseViolationFilter parses comma separated field=pattern terms, e.g.
+// "project=prod-*,label.env=prod" and "label.env=sandbox".
+func ParseViolationFilter(include, exclude string) (ViolationFilter, error) {
+	includeTerms, err := parseFilterTerms(include)
+	if err != nil {
+		return ViolationFilter{}, fmt.Errorf("include: %v", err)
+	}
+
+	excludeTerms, err := parseFilterTerms(exclude)
+	if err != nil {
+		return ViolationFilter{}, fmt.Errorf("exclude: %v", err)
+	}
+
+	return ViolationFilter{Include: includeTerms, Exclude: excludeTerms}, nil
+}
+
+// IsEmpty reports whether the filter keeps every violation.
+func (f ViolationFilter) IsEmpty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// String returns the canonical form of the filter, its sorted include and
+// exclude terms, so that runs can tell whether they scoped the same
+// violations. It is empty for the empty filter.
+func (f ViolationFilter) String() string {
+	if f.IsEmpty() {
+		return ""
+	}
+
+	return fmt.Sprintf("include=%s;exclude=%s", formatFilterTerms(f.Include), formatFilterTerms(f.Exclude))
+}
+
+// Apply returns the violations kept by the filter, in their original order.
+// The asset of each violation is decoded once when a term reads it, and a
+// payload that can not be decoded is an error rather than an asset matching
+// no term.
+func (f ViolationFilter) Apply(violations []Violation) ([]Violation, error) {
+	if f.IsEmpty() {
+		return violations, nil
+	}
+
+	readsAsset := false
+	for _, term := range append(append([]FilterTerm{}, f.Include...), f.Exclude...) {
+		readsAsset = readsAsset || term.readsAsset()
+	}
+
+	filtered := []Violation{}
+	for _, violation := range violations {
+		var asset Asset
+		if readsAsset {
+			var err error
+			asset, err = ParseAsset(violation.ViolatedAsset.Asset)
+			if err != nil {
+				return nil, fmt.Errorf("asset %s of policy %s: ParseAsset(): %v", violation.AssetID, violation.PolicyID, err)
+			}
+		}
+		if f.keeps(violation, asset) {
+			filtered = append(filtered, violation)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (f ViolationFilter) keeps(violation Violation, asset Asset) bool {
+	for _, term := range f.Include {
+		if !term.matches(violation, asset) {
+			return false
+		}
+	}
+	for _, term := range f.Exclude {
+		if term.matches(violation, asset) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t FilterTerm) readsAsset() bool {
+	return t.Field != FILTER_ASSET_TYPE && t.Field != FILTER_POLICY_ID
+}
+
+func (t FilterTerm) matches(violation Violation, asset Asset) bool {
+	var value string
+	switch t.Field {
+	case FILTER_ASSET_TYPE:
+		value = violation.ViolatedAsset.AssetType
+	case FILTER_PROJECT:
+		value = asset.Project
+	case FILTER_REGION, FILTER_LOCATION:
+		value = asset.Location
+	case FILTER_POLICY_ID:
+		value = violation.PolicyID
+	default:
+		key, ok := strings.CutPrefix(t.Field, FILTER_LABEL_PREFIX)
+		if !ok {
+			return false
+		}
+		label, ok := asset.Labels[key]
+		if !ok {
+			return false
+		}
+		value = label
+	}
+
+	ok, _ := MatchGlob(t.Pattern, value)
+	return ok
+}
+
+func parseFilterTerms(terms string) ([]FilterTerm, error) {
+	if strings.TrimSpace(terms) == "" {
+		return nil, nil
+	}
+
+	filterTerms := []FilterTerm{}
+	for _, term := range strings.Split(terms, ",") {
+		field, pattern, ok := strings.Cut(strings.TrimSpace(term), "=")
+		if !ok || field == "" {
+			return nil, fmt.Errorf("invalid term %q, expected field=pattern", term)
+		}
+		if !isFilterField(field) {
+			return nil, fmt.Errorf("unknown field %q in term %q, expected assetType, project, region, location, policyId or label.<key>", field, term)
+		}
+		if err := ValidateGlob(pattern); err != nil {
+			return nil, err
+		}
+		filterTerms = append(filterTerms, FilterTerm{Field: field, Pattern: pattern})
+	}
+
+	return filterTerms, nil
+}
+
+func formatFilterTerms(terms []FilterTerm) string {
+	formatted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		formatted = append(formatted, term.Field+"="+term.Pattern)
+	}
+	sort.Strings(formatted)
+
+	return strings.Join(formatted, ",")
+}
+
+func isFilterField(field string) bool {
+	switch field {
+	case FILTER_ASSET_TYPE, FILTER_PROJECT, FILTER_REGION, FILTER_LOCATION, FILTER_POLICY_ID:
+		return true
+	}
+
+	key, ok := strings.CutPrefix(field, FILTER_LABEL_PREFIX)
+	return ok && key != ""
+}
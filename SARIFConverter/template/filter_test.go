@@ -0,0 +1,158 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseViolationFilter(t *testing.T) {
+	tests := []struct {
+		name           string
+		include        string
+		exclude        string
+		expectedFilter ViolationFilter
+		wantErr        bool
+	}{
+		{
+			name:    "IncludeAndExclude_Succeeds",
+			include: "project=prod-*, assetType=storage.googleapis.com/*",
+			exclude: "label.env=sandbox",
+			expectedFilter: ViolationFilter{
+				Include: []FilterTerm{{Field: "project", Pattern: "prod-*"}, {Field: "assetType", Pattern: "storage.googleapis.com/*"}},
+				Exclude: []FilterTerm{{Field: "label.env", Pattern: "sandbox"}},
+			},
+		},
+		{
+			name:           "Empty_Succeeds",
+			expectedFilter: ViolationFilter{},
+		},
+		{
+			name:    "MissingPattern_Failure",
+			include: "project",
+			wantErr: true,
+		},
+		{
+			name:    "UnknownField_Failure",
+			include: "severty=LOW",
+			wantErr: true,
+		},
+		{
+			name:    "UnprefixedLabel_Failure",
+			exclude: "env=sandbox",
+			wantErr: true,
+		},
+		{
+			name:    "InvalidPattern_Failure",
+			exclude: "policyId=[",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, err := ParseViolationFilter(test.include, test.exclude)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if diff := cmp.Diff(test.expectedFilter, filter); diff != "" {
+				t.Errorf("Expected filter (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestViolationFilterApply(t *testing.T) {
+	violations := []Violation{
+		{AssetID: "bucket", PolicyID: "P1", ViolatedAsset: AssetDetails{Asset: bucketAsset, AssetType: "storage.googleapis.com/Bucket"}},
+		{AssetID: "vm", PolicyID: "P2", ViolatedAsset: AssetDetails{Asset: instanceAsset, AssetType: "compute.googleapis.com/Instance"}},
+		{AssetID: "opaque", PolicyID: "P2", ViolatedAsset: AssetDetails{AssetType: "compute.googleapis.com/Instance"}},
+	}
+
+	tests := []struct {
+		name           string
+		include        string
+		exclude        string
+		expectedAssets []string
+	}{
+		{name: "NoFilter", expectedAssets: []string{"bucket", "vm", "opaque"}},
+		{name: "ExcludeLabel", exclude: "label.env=sandbox", expectedAssets: []string{"bucket", "opaque"}},
+		{name: "IncludeAssetType", include: "assetType=compute.googleapis.com/*", expectedAssets: []string{"vm", "opaque"}},
+		{name: "IncludeAnyAssetType", include: "assetType=*", expectedAssets: []string{"bucket", "vm", "opaque"}},
+		{name: "IncludeAllTerms", include: "policyId=P2,region=europe-*", expectedAssets: []string{"vm"}},
+		{name: "IncludeExplicitLabel", include: "label.team=storage", expectedAssets: []string{"bucket"}},
+		{name: "ExcludeProject", exclude: "project=prod-*", expectedAssets: []string{"vm", "opaque"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, err := ParseViolationFilter(test.include, test.exclude)
+			if err != nil {
+				t.Fatalf("ParseViolationFilter: %v", err)
+			}
+
+			filtered, err := filter.Apply(violations)
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+
+			assets := []string{}
+			for _, violation := range filtered {
+				assets = append(assets, violation.AssetID)
+			}
+
+			if diff := cmp.Diff(test.expectedAssets, assets); diff != "" {
+				t.Errorf("Expected assets (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestViolationFilterString(t *testing.T) {
+	filter := ViolationFilter{
+		Include: []FilterTerm{{Field: "project", Pattern: "prod-*"}, {Field: "label.env", Pattern: "prod"}},
+		Exclude: []FilterTerm{{Field: "policyId", Pattern: "P1"}},
+	}
+
+	if got, want := filter.String(), "include=label.env=prod,project=prod-*;exclude=policyId=P1"; got != want {
+		t.Errorf("Expected filter string %q, got: %q", want, got)
+	}
+	if got := (ViolationFilter{}).String(); got != "" {
+		t.Errorf("Expected an empty string for the empty filter, got: %q", got)
+	}
+}
+
+func TestViolationFilterApply_MalformedAsset(t *testing.T) {
+	violations := []Violation{{AssetID: "opaque", PolicyID: "P1", ViolatedAsset: AssetDetails{Asset: "Asset 1"}}}
+
+	for _, filter := range []ViolationFilter{
+		{Include: []FilterTerm{{Field: FILTER_PROJECT, Pattern: "prod-*"}}},
+		{Exclude: []FilterTerm{{Field: FILTER_LABEL_PREFIX + "env", Pattern: "sandbox"}}},
+	} {
+		if _, err := filter.Apply(violations); err == nil {
+			t.Errorf("Expected error for a malformed asset filtered by %v, got nil", filter)
+		}
+	}
+
+	filter := ViolationFilter{Include: []FilterTerm{{Field: FILTER_POLICY_ID, Pattern: "P1"}}}
+	if filtered, err := filter.Apply(violations); err != nil || len(filtered) != 1 {
+		t.Errorf("Expected the policy term not to decode the asset, got: %v, %v", filtered, err)
+	}
+}
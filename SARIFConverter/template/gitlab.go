@@ -0,0 +1,84 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+// GitLabSASTReport is the gl-sast-report.json security report read by GitLab.
+type GitLabSASTReport struct {
+	Version         string                `json:"version"`
+	Scan            GitLabScan            `json:"scan"`
+	Vulnerabilities []GitLabVulnerability `json:"vulnerabilities"`
+}
+
+type GitLabScan struct {
+	Analyzer  GitLabScanTool `json:"analyzer"`
+	Scanner   GitLabScanTool `json:"scanner"`
+	Type      string         `json:"type"`
+	StartTime string         `json:"start_time"`
+	EndTime   string         `json:"end_time"`
+	Status    string         `json:"status"`
+}
+
+type GitLabScanTool struct {
+	ID      string       `json:"id"`
+	Name    string       `json:"name"`
+	Version string       `json:"version"`
+	Vendor  GitLabVendor `json:"vendor"`
+}
+
+type GitLabVendor struct {
+	Name string `json:"name"`
+}
+
+type GitLabVulnerability struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Severity    string             `json:"severity"`
+	Solution    string             `json:"solution,omitempty"`
+	Identifiers []GitLabIdentifier `json:"identifiers"`
+	Location    GitLabLocation     `json:"location"`
+}
+
+type GitLabIdentifier struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	URL   string `json:"url,omitempty"`
+}
+
+type GitLabLocation struct {
+	File  string `json:"file"`
+	Class string `json:"class,omitempty"`
+}
+
+// CodeQualityIssue is an entry of the GitLab Code Quality report.
+type CodeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    CodeQualityLocation `json:"location"`
+}
+
+type CodeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines CodeQualityLines `json:"lines"`
+}
+
+type CodeQualityLines struct {
+	Begin int `json:"begin"`
+}
@@ -0,0 +1,86 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MatchGlob reports whether the value matches the glob pattern. Unlike
+// path.Match, "*" and "**" match any sequence of characters including "/", as
+// in CODEOWNERS, so that "//compute.googleapis.com/projects/p/*" matches the
+// asset names of the project. "?" matches any character, "[...]" a character
+// class, negated with "!" or "^", and "\" escapes the next character.
+func MatchGlob(pattern, value string) (bool, error) {
+	re, err := globRegexp(pattern)
+	if err != nil {
+		return false, err
+	}
+
+	return re.MatchString(value), nil
+}
+
+// ValidateGlob returns an error when the glob pattern is malformed.
+func ValidateGlob(pattern string) error {
+	_, err := globRegexp(pattern)
+	return err
+}
+
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString(`(?s)^`)
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			for i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+			}
+			b.WriteString(`.*`)
+		case '?':
+			b.WriteString(`.`)
+		case '\\':
+			if i+1 == len(pattern) {
+				return nil, fmt.Errorf("trailing escape in %q", pattern)
+			}
+			i++
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end <= 0 {
+				return nil, fmt.Errorf("unterminated character class in %q", pattern)
+			}
+			class := pattern[i+1 : i+1+end]
+			if class[0] == '!' {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, "[", `\[`) + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	b.WriteString(`$`)
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+
+	return re, nil
+}
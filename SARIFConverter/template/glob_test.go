@@ -0,0 +1,62 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+import (
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		value    string
+		expected bool
+	}{
+		{name: "StarCrossesSlashes", pattern: "*", value: "//storage.googleapis.com/logs", expected: true},
+		{name: "ProjectAssets", pattern: "//compute.googleapis.com/projects/net-*/*", value: "//compute.googleapis.com/projects/net-prod/global/firewalls/fw", expected: true},
+		{name: "DoubleStar", pattern: "//compute.googleapis.com/**/instances/*", value: "//compute.googleapis.com/projects/p/zones/z/instances/vm", expected: true},
+		{name: "OtherService", pattern: "//compute.googleapis.com/*", value: "//storage.googleapis.com/logs", expected: false},
+		{name: "Anchored", pattern: "logs", value: "prod-logs", expected: false},
+		{name: "QuestionMark", pattern: "vm-?", value: "vm-1", expected: true},
+		{name: "Class", pattern: "vm-[0-9]", value: "vm-a", expected: false},
+		{name: "NegatedClass", pattern: "vm-[!0-9]", value: "vm-a", expected: true},
+		{name: "EscapedStar", pattern: `prod-\*`, value: "prod-app", expected: false},
+		{name: "EscapedStarLiteral", pattern: `prod-\*`, value: "prod-*", expected: true},
+		{name: "RegexpMetacharacters", pattern: "a.b+(c)", value: "a.b+(c)", expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matched, err := MatchGlob(test.pattern, test.value)
+			if err != nil {
+				t.Fatalf("MatchGlob(%q): %v", test.pattern, err)
+			}
+			if matched != test.expected {
+				t.Errorf("Expected MatchGlob(%q, %q) to be %v, got: %v", test.pattern, test.value, test.expected, matched)
+			}
+		})
+	}
+}
+
+func TestValidateGlob_Failure(t *testing.T) {
+	for _, pattern := range []string{"[", "a[]", `a\`, "[z-a]"} {
+		if err := ValidateGlob(pattern); err == nil {
+			t.Errorf("Expected an error for the pattern %q", pattern)
+		}
+	}
+}
@@ -29,6 +29,21 @@ type Responses struct {
 	IacValidationReport IACValidationReport `json:"iacValidationReport,omitempty"`
 }
 
+// Operation is the long-running operation printed by gcloud scc
+// iac-validation-reports create once done.
+type Operation struct {
+	Name     string    `json:"name,omitempty"`
+	Done     bool      `json:"done"`
+	Error    *Status   `json:"error,omitempty"`
+	Response Responses `json:"response"`
+}
+
+// Status is the error of a failed operation.
+type Status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
 type IACValidationReport struct {
 	Violations []Violation `json:"violations,omitempty"`
 	Note       string      `json:"note,omitempty"`
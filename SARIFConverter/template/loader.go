@@ -0,0 +1,175 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseReport auto-detects the shape of an SCC IAC validation report and
+// returns it normalized. It accepts the long-running operation wrapper, the
+// bare report resource, the bare IacValidationReport, the JSON list printed by
+// gcloud --format=json and the YAML printed by gcloud by default. A list must
+// hold a single report: picking one of several would silently discard the
+// others.
+func ParseReport(data []byte) (Responses, error) {
+	report, _, err := parseReport(data)
+	return report, err
+}
+
+// ReadReport reads and normalizes a report, see ParseReport.
+func ReadReport(r io.Reader) (Responses, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Responses{}, fmt.Errorf("io.ReadAll(): %v", err)
+	}
+
+	return ParseReport(data)
+}
+
+// parseReport also returns the JSON path of the IacValidationReport within
+// the input, used to point diagnostics at the right place.
+func parseReport(data []byte) (Responses, string, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return Responses{}, "", fmt.Errorf("empty report")
+	}
+
+	var document interface{}
+	if trimmed[0] == '{' || trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &document); err != nil {
+			return Responses{}, "", fmt.Errorf("json.Unmarshal(): %v", err)
+		}
+	} else {
+		documents, err := decodeYAMLDocuments(trimmed)
+		if err != nil {
+			return Responses{}, "", err
+		}
+		document = documents
+		if len(documents) == 1 {
+			document = documents[0]
+		}
+	}
+
+	return normalizeReport(document, "$")
+}
+
+func decodeYAMLDocuments(data []byte) ([]interface{}, error) {
+	documents := []interface{}{}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var document interface{}
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("yaml.Decode(): %v", err)
+		}
+		if document != nil {
+			documents = append(documents, document)
+		}
+	}
+
+	return documents, nil
+}
+
+func normalizeReport(document interface{}, path string) (Responses, string, error) {
+	switch doc := document.(type) {
+	case []interface{}:
+		return singleReport(doc, path)
+	case map[string]interface{}:
+		return normalizeReportObject(doc, path)
+	default:
+		return Responses{}, "", fmt.Errorf("%s: unrecognized report format: expected an object or a list, got %T", path, document)
+	}
+}
+
+func normalizeReportObject(doc map[string]interface{}, path string) (Responses, string, error) {
+	if response, ok := doc["response"]; ok {
+		if opErr, ok := doc["error"]; ok {
+			return Responses{}, "", fmt.Errorf("operation %v failed: %v", doc["name"], opErr)
+		}
+		if done, ok := doc["done"].(bool); ok && !done {
+			return Responses{}, "", fmt.Errorf("operation %v is not done", doc["name"])
+		}
+		responseObject, ok := response.(map[string]interface{})
+		if !ok {
+			return Responses{}, "", fmt.Errorf("%s.response: unrecognized report format: expected an object, got %T", path, response)
+		}
+		return normalizeReportObject(responseObject, path+".response")
+	}
+
+	if _, ok := doc["iacValidationReport"]; ok {
+		var report Responses
+		if err := remarshal(doc, &report); err != nil {
+			return Responses{}, "", fmt.Errorf("%s: %v", path, err)
+		}
+		return report, path + ".iacValidationReport", nil
+	}
+
+	_, hasViolations := doc["violations"]
+	_, hasNote := doc["note"]
+	if hasViolations || hasNote {
+		var report Responses
+		if err := remarshal(doc, &report.IacValidationReport); err != nil {
+			return Responses{}, "", fmt.Errorf("%s: %v", path, err)
+		}
+		return report, path, nil
+	}
+
+	return Responses{}, "", fmt.Errorf("%s: unrecognized report format: none of response, iacValidationReport or violations found", path)
+}
+
+func singleReport(docs []interface{}, path string) (Responses, string, error) {
+	if len(docs) == 0 {
+		return Responses{}, "", fmt.Errorf("%s: empty report list", path)
+	}
+	if len(docs) > 1 {
+		names := make([]string, 0, len(docs))
+		for _, doc := range docs {
+			if fields, ok := doc.(map[string]interface{}); ok {
+				names = append(names, fmt.Sprint(fields["name"]))
+			}
+		}
+		return Responses{}, "", fmt.Errorf("%s: the list holds %d reports (%s), expected one, e.g. from gcloud scc iac-validation-reports describe or list --limit=1", path, len(docs), strings.Join(names, ", "))
+	}
+
+	return normalizeReport(docs[0], path+"[0]")
+}
+
+// remarshal converts a generically decoded document into the typed template.
+func remarshal(doc interface{}, v interface{}) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("json.Marshal(): %v", err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("json.Unmarshal(): %v", err)
+	}
+
+	return nil
+}
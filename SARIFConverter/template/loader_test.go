@@ -0,0 +1,171 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var expectedReport = Responses{
+	Name:       "organizations/1/locations/global/reports/r1",
+	CreateTime: "2024-05-01T10:00:00Z",
+	UpdateTime: "2024-05-01T10:05:00Z",
+	IacValidationReport: IACValidationReport{
+		Note: "Test Note",
+		Violations: []Violation{
+			{AssetID: "asset1", PolicyID: "policy1", Severity: "HIGH"},
+		},
+	},
+}
+
+func TestParseReport(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedReport Responses
+		wantErr        bool
+	}{
+		{
+			name: "OperationWrapper_Succeeds",
+			input: `{"name": "organizations/1/locations/global/operations/op1", "done": true, "response": {
+				"@type": "type.googleapis.com/google.cloud.securityposture.v1.Report",
+				"name": "organizations/1/locations/global/reports/r1",
+				"createTime": "2024-05-01T10:00:00Z", "updateTime": "2024-05-01T10:05:00Z",
+				"iacValidationReport": {"note": "Test Note", "violations": [{"assetId": "asset1", "policyId": "policy1", "severity": "HIGH"}]}}}`,
+			expectedReport: expectedReport,
+		},
+		{
+			name: "ReportResource_Succeeds",
+			input: `{"name": "organizations/1/locations/global/reports/r1",
+				"createTime": "2024-05-01T10:00:00Z", "updateTime": "2024-05-01T10:05:00Z",
+				"iacValidationReport": {"note": "Test Note", "violations": [{"assetId": "asset1", "policyId": "policy1", "severity": "HIGH"}]}}`,
+			expectedReport: expectedReport,
+		},
+		{
+			name:           "BareIacValidationReport_Succeeds",
+			input:          `{"note": "Test Note", "violations": [{"assetId": "asset1", "policyId": "policy1", "severity": "HIGH"}]}`,
+			expectedReport: Responses{IacValidationReport: expectedReport.IacValidationReport},
+		},
+		{
+			name: "ListOutput_Succeeds",
+			input: `[
+				{"name": "organizations/1/locations/global/reports/r1",
+				 "createTime": "2024-05-01T10:00:00Z", "updateTime": "2024-05-01T10:05:00Z",
+				 "iacValidationReport": {"note": "Test Note", "violations": [{"assetId": "asset1", "policyId": "policy1", "severity": "HIGH"}]}}]`,
+			expectedReport: expectedReport,
+		},
+		{
+			name: "YAMLOutput_Succeeds",
+			input: `done: true
+name: organizations/1/locations/global/operations/op1
+response:
+  createTime: '2024-05-01T10:00:00Z'
+  iacValidationReport:
+    note: Test Note
+    violations:
+    - assetId: asset1
+      policyId: policy1
+      severity: HIGH
+  name: organizations/1/locations/global/reports/r1
+  updateTime: '2024-05-01T10:05:00Z'
+`,
+			expectedReport: expectedReport,
+		},
+		{
+			name: "YAMLListOutput_Succeeds",
+			input: `---
+createTime: '2024-05-01T10:00:00Z'
+iacValidationReport:
+  note: Test Note
+  violations:
+  - assetId: asset1
+    policyId: policy1
+    severity: HIGH
+name: organizations/1/locations/global/reports/r1
+updateTime: '2024-05-01T10:05:00Z'
+`,
+			expectedReport: expectedReport,
+		},
+		{
+			name: "ListOfReports_Failure",
+			input: `[
+				{"name": "organizations/1/locations/global/reports/r0", "createTime": "2024-04-01T10:00:00Z", "iacValidationReport": {"note": "Old"}},
+				{"name": "organizations/1/locations/global/reports/r1", "createTime": "2024-05-01T10:00:00Z", "iacValidationReport": {"note": "New"}}]`,
+			wantErr: true,
+		},
+		{
+			name: "YAMLListOfReports_Failure",
+			input: `---
+name: organizations/1/locations/global/reports/r0
+iacValidationReport:
+  note: Old
+---
+name: organizations/1/locations/global/reports/r1
+iacValidationReport:
+  note: New
+`,
+			wantErr: true,
+		},
+		{
+			name:    "MissingResponse_Failure",
+			input:   `{"name": "organizations/1/locations/global/operations/op1", "done": true}`,
+			wantErr: true,
+		},
+		{
+			name:    "OperationNotDone_Failure",
+			input:   `{"name": "organizations/1/locations/global/operations/op1", "done": false, "response": {}}`,
+			wantErr: true,
+		},
+		{
+			name:    "OperationError_Failure",
+			input:   `{"name": "op1", "done": true, "error": {"code": 3, "message": "bad plan"}, "response": {}}`,
+			wantErr: true,
+		},
+		{
+			name:    "EmptyInput_Failure",
+			input:   "  \n",
+			wantErr: true,
+		},
+		{
+			name:    "EmptyList_Failure",
+			input:   "[]",
+			wantErr: true,
+		},
+		{
+			name:    "MalformedJSON_Failure",
+			input:   `{"response": `,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			report, err := ParseReport([]byte(test.input))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if diff := cmp.Diff(test.expectedReport, report); diff != "" {
+				t.Errorf("Expected report (+got, -want): %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+// WaiverFile is the list of accepted risks passed as an input.
+type WaiverFile struct {
+	Waivers []Waiver `json:"waivers"`
+}
+
+// Waiver accepts the risk of a policy violation. AssetID is a glob pattern,
+// an empty AssetID waives the policy on every asset.
+type Waiver struct {
+	PolicyID      string `json:"policyId"`
+	AssetID       string `json:"assetId,omitempty"`
+	Justification string `json:"justification"`
+	Author        string `json:"author"`
+	Timestamp     string `json:"timestamp,omitempty"`
+}
+
+// OpenVEXDocument is an OpenVEX document publishing accepted risks.
+type OpenVEXDocument struct {
+	Context    string             `json:"@context"`
+	ID         string             `json:"@id"`
+	Author     string             `json:"author"`
+	Timestamp  string             `json:"timestamp"`
+	Version    int                `json:"version"`
+	Tooling    string             `json:"tooling,omitempty"`
+	Statements []OpenVEXStatement `json:"statements"`
+}
+
+type OpenVEXStatement struct {
+	Vulnerability   OpenVEXVulnerability `json:"vulnerability"`
+	Products        []OpenVEXProduct     `json:"products"`
+	Status          string               `json:"status"`
+	StatusNotes     string               `json:"status_notes,omitempty"`
+	ActionStatement string               `json:"action_statement,omitempty"`
+	Timestamp       string               `json:"timestamp,omitempty"`
+}
+
+type OpenVEXVulnerability struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+type OpenVEXProduct struct {
+	ID string `json:"@id"`
+}
@@ -0,0 +1,153 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+const (
+	OWNERSHIP_TYPE_PREFIX = "type:"
+	OWNERSHIP_PATH_PREFIX = "path:"
+)
+
+// Ownership maps assets to the teams owning them, like a CODEOWNERS file.
+type Ownership struct {
+	Rules []OwnershipRule
+}
+
+// OwnershipRule assigns the owners to the assets matching the pattern. The
+// pattern is a MatchGlob glob compared against the asset ID, against the
+// asset type when prefixed with "type:" or against the source file of the
+// asset when prefixed with "path:". A pattern ending with a slash matches
+// every file under that directory.
+type OwnershipRule struct {
+	Pattern string
+	Owners  []string
+}
+
+// LoadOwnership reads an ownership file, see ParseOwnership.
+func LoadOwnership(filePath string) (Ownership, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return Ownership{}, fmt.Errorf("os.ReadFile(%s): %v", filePath, err)
+	}
+
+	return ParseOwnership(data)
+}
+
+// LoadFileMapping reads the JSON object mapping asset IDs to the source files
+// declaring them. An empty path maps no asset.
+func LoadFileMapping(filePath string) (map[string]string, error) {
+	if filePath == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile(%s): %v", filePath, err)
+	}
+
+	var mapping map[string]string
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(): %v", err)
+	}
+
+	return mapping, nil
+}
+
+// ParseOwnership parses a CODEOWNERS-style file: one pattern per line followed
+// by its owners, separated by spaces. Blank lines and lines starting with #
+// are ignored.
+func ParseOwnership(data []byte) (Ownership, error) {
+	ownership := Ownership{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		rule := OwnershipRule{Pattern: fields[0], Owners: fields[1:]}
+		if err := ValidateGlob(rule.glob()); err != nil {
+			return Ownership{}, fmt.Errorf("line %d: %v", line, err)
+		}
+		ownership.Rules = append(ownership.Rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return Ownership{}, fmt.Errorf("scanner.Scan(): %v", err)
+	}
+
+	return ownership, nil
+}
+
+// Owners returns the owners of the violated asset. As in CODEOWNERS, the last
+// matching rule wins. sourceFiles maps asset IDs to their source file and may
+// be nil.
+func (o Ownership) Owners(violation Violation, sourceFiles map[string]string) []string {
+	for i := len(o.Rules) - 1; i >= 0; i-- {
+		if o.Rules[i].matches(violation, sourceFiles) {
+			return o.Rules[i].Owners
+		}
+	}
+
+	return nil
+}
+
+// IsOwnedBy reports whether the owner is among the owners of the violated
+// asset.
+func (o Ownership) IsOwnedBy(violation Violation, sourceFiles map[string]string, owner string) bool {
+	return slices.Contains(o.Owners(violation, sourceFiles), owner)
+}
+
+func (r OwnershipRule) matches(violation Violation, sourceFiles map[string]string) bool {
+	value := violation.AssetID
+	switch {
+	case strings.HasPrefix(r.Pattern, OWNERSHIP_TYPE_PREFIX):
+		value = violation.ViolatedAsset.AssetType
+	case strings.HasPrefix(r.Pattern, OWNERSHIP_PATH_PREFIX):
+		sourceFile, ok := sourceFiles[violation.AssetID]
+		if !ok {
+			return false
+		}
+		value = sourceFile
+		if strings.HasSuffix(r.Pattern, "/") {
+			return strings.HasPrefix(sourceFile, strings.TrimPrefix(r.Pattern, OWNERSHIP_PATH_PREFIX))
+		}
+	}
+
+	ok, _ := MatchGlob(r.glob(), value)
+	return ok
+}
+
+func (r OwnershipRule) glob() string {
+	return strings.TrimPrefix(strings.TrimPrefix(r.Pattern, OWNERSHIP_TYPE_PREFIX), OWNERSHIP_PATH_PREFIX)
+}
+
+// OwnerSummary counts the violations on the assets of an owner.
+type OwnerSummary struct {
+	Owner      string         `json:"owner"`
+	Total      int            `json:"total"`
+	Severities map[string]int `json:"severities"`
+}
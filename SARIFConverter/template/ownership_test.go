@@ -0,0 +1,107 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testOwnersFile = `
+# Default owner of every asset.
+*                                          @platform-team
+
+type:storage.googleapis.com/Bucket         @storage-team
+//compute.googleapis.com/projects/net-*/*  @network-team @platform-team
+path:modules/sandbox/                      @sandbox-team
+path:modules/legacy/*.tf
+`
+
+func TestParseOwnership(t *testing.T) {
+	ownership, err := ParseOwnership([]byte(testOwnersFile))
+	if err != nil {
+		t.Fatalf("ParseOwnership: %v", err)
+	}
+
+	expected := Ownership{Rules: []OwnershipRule{
+		{Pattern: "*", Owners: []string{"@platform-team"}},
+		{Pattern: "type:storage.googleapis.com/Bucket", Owners: []string{"@storage-team"}},
+		{Pattern: "//compute.googleapis.com/projects/net-*/*", Owners: []string{"@network-team", "@platform-team"}},
+		{Pattern: "path:modules/sandbox/", Owners: []string{"@sandbox-team"}},
+		{Pattern: "path:modules/legacy/*.tf", Owners: []string{}},
+	}}
+	if diff := cmp.Diff(expected, ownership); diff != "" {
+		t.Errorf("Expected ownership (+got, -want): %v", diff)
+	}
+
+	if _, err := ParseOwnership([]byte("type:[ @team")); err == nil {
+		t.Errorf("Expected an error for an invalid pattern")
+	}
+}
+
+func TestOwnershipOwners(t *testing.T) {
+	ownership, err := ParseOwnership([]byte(testOwnersFile))
+	if err != nil {
+		t.Fatalf("ParseOwnership: %v", err)
+	}
+
+	sourceFiles := map[string]string{
+		"//compute.googleapis.com/projects/p/zones/z/instances/vm":     "modules/sandbox/vm/main.tf",
+		"//compute.googleapis.com/projects/p/zones/z/instances/legacy": "modules/legacy/main.tf",
+	}
+
+	tests := []struct {
+		name           string
+		violation      Violation
+		expectedOwners []string
+	}{
+		{
+			name:           "DefaultRule",
+			violation:      Violation{AssetID: "//sqladmin.googleapis.com/projects/p/instances/db"},
+			expectedOwners: []string{"@platform-team"},
+		},
+		{
+			name:           "AssetType",
+			violation:      Violation{AssetID: "//storage.googleapis.com/logs", ViolatedAsset: AssetDetails{AssetType: "storage.googleapis.com/Bucket"}},
+			expectedOwners: []string{"@storage-team"},
+		},
+		{
+			name:           "AssetName_LastMatchWins",
+			violation:      Violation{AssetID: "//compute.googleapis.com/projects/net-prod/global/firewalls/fw", ViolatedAsset: AssetDetails{AssetType: "storage.googleapis.com/Bucket"}},
+			expectedOwners: []string{"@network-team", "@platform-team"},
+		},
+		{
+			name:           "ModuleDirectory",
+			violation:      Violation{AssetID: "//compute.googleapis.com/projects/p/zones/z/instances/vm"},
+			expectedOwners: []string{"@sandbox-team"},
+		},
+		{
+			name:           "ModuleWithoutOwner",
+			violation:      Violation{AssetID: "//compute.googleapis.com/projects/p/zones/z/instances/legacy"},
+			expectedOwners: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.expectedOwners, ownership.Owners(test.violation, sourceFiles)); diff != "" {
+				t.Errorf("Expected owners (+got, -want): %v", diff)
+			}
+		})
+	}
+}
@@ -23,9 +23,40 @@ type SarifOutput struct {
 }
 
 type Run struct {
-	Note    string   `json:"note,omitempty"`
-	Tool    Tool     `json:"tool,omitempty"`
-	Results []Result `json:"results,omitempty"`
+	Note              string                `json:"note,omitempty"`
+	Tool              Tool                  `json:"tool,omitempty"`
+	AutomationDetails *RunAutomationDetails `json:"automationDetails,omitempty"`
+	Results           []Result              `json:"results,omitempty"`
+	Invocations       []Invocation          `json:"invocations,omitempty"`
+	Properties        *RunProperties        `json:"properties,omitempty"`
+}
+
+type Invocation struct {
+	ExecutionSuccessful        bool           `json:"executionSuccessful"`
+	ToolExecutionNotifications []Notification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type Notification struct {
+	Level          string         `json:"level,omitempty"`
+	Message        Message        `json:"message"`
+	AssociatedRule *RuleReference `json:"associatedRule,omitempty"`
+}
+
+type RuleReference struct {
+	ID string `json:"id,omitempty"`
+}
+
+type RunAutomationDetails struct {
+	ID          string   `json:"id,omitempty"`
+	Description *Message `json:"description,omitempty"`
+}
+
+type RunProperties struct {
+	Posture                         string `json:"posture,omitempty"`
+	PostureRevisionID               string `json:"postureRevisionId,omitempty"`
+	PostureDeploymentID             string `json:"postureDeploymentId,omitempty"`
+	PostureDeploymentTargetResource string `json:"postureDeploymentTargetResource,omitempty"`
+	RiskScore                       *Score `json:"riskScore,omitempty"`
 }
 
 type Tool struct {
@@ -40,15 +71,24 @@ type Driver struct {
 }
 
 type Rule struct {
-	ID              string          `json:"id,omitempty"`
-	FullDescription FullDescription `json:"fullDescription,omitempty"`
-	Properties      RuleProperties  `json:"properties,omitempty"`
+	ID               string              `json:"id,omitempty"`
+	Name             string              `json:"name,omitempty"`
+	ShortDescription *MultiformatMessage `json:"shortDescription,omitempty"`
+	FullDescription  FullDescription     `json:"fullDescription,omitempty"`
+	Help             *MultiformatMessage `json:"help,omitempty"`
+	HelpURI          string              `json:"helpUri,omitempty"`
+	Properties       RuleProperties      `json:"properties,omitempty"`
 }
 
 type FullDescription struct {
 	Text string `json:"text,omitempty"`
 }
 
+type MultiformatMessage struct {
+	Text     string `json:"text,omitempty"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
 type RuleProperties struct {
 	Severity            string   `json:"severity,omitempty"`
 	PolicyType          string   `json:"policyType,omitempty"`
@@ -59,6 +99,9 @@ type RuleProperties struct {
 	PostureDeploymentID string   `json:"postureDeploymentId,omitempty"`
 	Constraints         string   `json:"constraints,omitempty"`
 	NextSteps           string   `json:"nextSteps,omitempty"`
+	Owner               string   `json:"owner,omitempty"`
+	RunbookURL          string   `json:"runbookUrl,omitempty"`
+	Tags                []string `json:"tags,omitempty"`
 }
 
 type Result struct {
@@ -81,7 +124,20 @@ type LogicalLocation struct {
 }
 
 type ResultProperties struct {
-	AssetID   string `json:"assetId,omitempty"`
-	AssetType string `json:"assetType,omitempty"`
-	Asset     string `json:"asset,omitempty"`
+	// Severity is the severity of the violation, overridden by the risk
+	// rating of the catalog like the severity of its rule.
+	Severity   string            `json:"severity,omitempty"`
+	AssetID    string            `json:"assetId,omitempty"`
+	AssetType  string            `json:"assetType,omitempty"`
+	Asset      string            `json:"asset,omitempty"`
+	AssetName  string            `json:"assetName,omitempty"`
+	Project    string            `json:"project,omitempty"`
+	Location   string            `json:"location,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Owner      string            `json:"owner,omitempty"`
+	RunbookURL string            `json:"runbookUrl,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+	// AssetOwners are the teams owning the asset, unlike Owner which is the
+	// team owning the policy.
+	AssetOwners []string `json:"assetOwners,omitempty"`
 }
@@ -0,0 +1,119 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ScoreWeights configures the risk score of a report. The score of a
+// violation is the weight of its severity, multiplied by the factors of its
+// policy and of its asset type when set.
+type ScoreWeights struct {
+	Severities map[string]float64 `json:"severities"`
+	Policies   map[string]float64 `json:"policies,omitempty"`
+	AssetTypes map[string]float64 `json:"assetTypes,omitempty"`
+}
+
+// DefaultScoreWeights weighs the severities when no weights are configured.
+var DefaultScoreWeights = ScoreWeights{
+	Severities: map[string]float64{
+		string(SeverityCritical): 10,
+		string(SeverityHigh):     5,
+		string(SeverityMedium):   2,
+		string(SeverityLow):      1,
+	},
+}
+
+// Score is the risk score of a set of violations and its breakdown.
+type Score struct {
+	Total       float64            `json:"total"`
+	BySeverity  map[string]float64 `json:"bySeverity"`
+	ByPolicy    map[string]float64 `json:"byPolicy"`
+	ByAssetType map[string]float64 `json:"byAssetType"`
+}
+
+// LoadScoreWeights reads score weights from a JSON file. Severities without
+// weight take their default weight.
+func LoadScoreWeights(filePath string) (ScoreWeights, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return ScoreWeights{}, fmt.Errorf("os.ReadFile(%s): %v", filePath, err)
+	}
+
+	var weights ScoreWeights
+	if err := json.Unmarshal(data, &weights); err != nil {
+		return ScoreWeights{}, fmt.Errorf("json.Unmarshal(): %v", err)
+	}
+
+	severities := make(map[string]float64)
+	for severity, weight := range DefaultScoreWeights.Severities {
+		severities[severity] = weight
+	}
+	for severity, weight := range weights.Severities {
+		if !ParseSeverity(severity).IsKnown() {
+			return ScoreWeights{}, fmt.Errorf("unknown severity %q", severity)
+		}
+		severities[string(ParseSeverity(severity))] = weight
+	}
+	weights.Severities = severities
+
+	for name, factors := range map[string]map[string]float64{"severity": severities, "policy": weights.Policies, "asset type": weights.AssetTypes} {
+		for key, factor := range factors {
+			if factor < 0 {
+				return ScoreWeights{}, fmt.Errorf("negative %s weight for %q", name, key)
+			}
+		}
+	}
+
+	return weights, nil
+}
+
+// ComputeScore sums the score of the violations. The zero ScoreWeights uses
+// DefaultScoreWeights.
+func ComputeScore(violations []Violation, weights ScoreWeights) Score {
+	if weights.Severities == nil {
+		weights.Severities = DefaultScoreWeights.Severities
+	}
+
+	score := Score{
+		BySeverity:  make(map[string]float64),
+		ByPolicy:    make(map[string]float64),
+		ByAssetType: make(map[string]float64),
+	}
+
+	for _, v := range violations {
+		severity := strings.ToUpper(v.Severity)
+		points := weights.Severities[severity]
+		if factor, ok := weights.Policies[v.PolicyID]; ok {
+			points *= factor
+		}
+		if factor, ok := weights.AssetTypes[v.ViolatedAsset.AssetType]; ok {
+			points *= factor
+		}
+
+		score.Total += points
+		score.BySeverity[severity] += points
+		score.ByPolicy[v.PolicyID] += points
+		score.ByAssetType[v.ViolatedAsset.AssetType] += points
+	}
+
+	return score
+}
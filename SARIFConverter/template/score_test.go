@@ -0,0 +1,117 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestComputeScore(t *testing.T) {
+	violations := []Violation{
+		{PolicyID: "P1", Severity: "CRITICAL", ViolatedAsset: AssetDetails{AssetType: "storage.googleapis.com/Bucket"}},
+		{PolicyID: "P2", Severity: "high", ViolatedAsset: AssetDetails{AssetType: "compute.googleapis.com/Instance"}},
+		{PolicyID: "P2", Severity: "LOW", ViolatedAsset: AssetDetails{AssetType: "compute.googleapis.com/Instance"}},
+	}
+
+	tests := []struct {
+		name          string
+		weights       ScoreWeights
+		expectedScore Score
+	}{
+		{
+			name: "DefaultWeights_Succeeds",
+			expectedScore: Score{
+				Total:       16,
+				BySeverity:  map[string]float64{"CRITICAL": 10, "HIGH": 5, "LOW": 1},
+				ByPolicy:    map[string]float64{"P1": 10, "P2": 6},
+				ByAssetType: map[string]float64{"storage.googleapis.com/Bucket": 10, "compute.googleapis.com/Instance": 6},
+			},
+		},
+		{
+			name: "PolicyAndAssetTypeFactors_Succeeds",
+			weights: ScoreWeights{
+				Severities: map[string]float64{"CRITICAL": 20, "HIGH": 5, "LOW": 1},
+				Policies:   map[string]float64{"P2": 2},
+				AssetTypes: map[string]float64{"storage.googleapis.com/Bucket": 0.5},
+			},
+			expectedScore: Score{
+				Total:       22,
+				BySeverity:  map[string]float64{"CRITICAL": 10, "HIGH": 10, "LOW": 2},
+				ByPolicy:    map[string]float64{"P1": 10, "P2": 12},
+				ByAssetType: map[string]float64{"storage.googleapis.com/Bucket": 10, "compute.googleapis.com/Instance": 12},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.expectedScore, ComputeScore(violations, test.weights)); diff != "" {
+				t.Errorf("Expected score (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestLoadScoreWeights(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         string
+		expectedWeights ScoreWeights
+		wantErr         bool
+	}{
+		{
+			name:    "MergedWithDefaults_Succeeds",
+			content: `{"severities": {"critical": 25}, "policies": {"P1": 2}}`,
+			expectedWeights: ScoreWeights{
+				Severities: map[string]float64{"CRITICAL": 25, "HIGH": 5, "MEDIUM": 2, "LOW": 1},
+				Policies:   map[string]float64{"P1": 2},
+			},
+		},
+		{
+			name:    "UnknownSeverity_Failure",
+			content: `{"severities": {"URGENT": 25}}`,
+			wantErr: true,
+		},
+		{
+			name:    "NegativeFactor_Failure",
+			content: `{"assetTypes": {"storage.googleapis.com/Bucket": -1}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "weights.json")
+			if err := os.WriteFile(filePath, []byte(test.content), 0644); err != nil {
+				t.Fatalf("os.WriteFile(): %v", err)
+			}
+
+			weights, err := LoadScoreWeights(filePath)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if diff := cmp.Diff(test.expectedWeights, weights); diff != "" {
+				t.Errorf("Expected weights (+got, -want): %v", diff)
+			}
+		})
+	}
+}
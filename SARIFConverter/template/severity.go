@@ -0,0 +1,134 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is the severity of a violation as reported by the SCC API.
+type Severity string
+
+const (
+	SeverityUnspecified Severity = "SEVERITY_UNSPECIFIED"
+	SeverityCritical    Severity = "CRITICAL"
+	SeverityHigh        Severity = "HIGH"
+	SeverityMedium      Severity = "MEDIUM"
+	SeverityLow         Severity = "LOW"
+)
+
+// KnownSeverities lists the severities failure criteria can be set on, most
+// severe first.
+var KnownSeverities = []Severity{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow}
+
+// ParseSeverity normalizes the case of a severity. Values outside of the API
+// enum are returned as is and reported as unknown by IsKnown.
+func ParseSeverity(severity string) Severity {
+	return Severity(strings.ToUpper(strings.TrimSpace(severity)))
+}
+
+// IsKnown reports whether a threshold can be set on the severity.
+// SEVERITY_UNSPECIFIED is part of the API enum but is not known in this sense.
+func (s Severity) IsKnown() bool {
+	return s.Rank() > 0
+}
+
+// Rank orders severities, higher is more severe and 0 means unknown.
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityCritical:
+		return 4
+	case SeverityHigh:
+		return 3
+	case SeverityMedium:
+		return 2
+	case SeverityLow:
+		return 1
+	default:
+		return 0
+	}
+}
+
+const (
+	UnknownSeverityFail    = "fail"
+	UnknownSeverityIgnore  = "ignore"
+	UnknownSeverityTreatAs = "treat-as"
+)
+
+// UnknownSeverityPolicy decides what happens to violations whose severity is
+// SEVERITY_UNSPECIFIED or not part of the API enum. The zero value fails.
+type UnknownSeverityPolicy struct {
+	Action string
+	// TreatAs is the severity unknown values are rewritten to when Action is
+	// UnknownSeverityTreatAs.
+	TreatAs Severity
+}
+
+// Fails reports whether the policy rejects unknown severities.
+func (p UnknownSeverityPolicy) Fails() bool {
+	return p.Action != UnknownSeverityIgnore && p.Action != UnknownSeverityTreatAs
+}
+
+// ParseUnknownSeverityPolicy parses "fail", "ignore" or "treat-as-<severity>",
+// e.g. "treat-as-low". An empty value selects "fail".
+func ParseUnknownSeverityPolicy(policy string) (UnknownSeverityPolicy, error) {
+	switch value := strings.ToLower(strings.TrimSpace(policy)); {
+	case value == "" || value == UnknownSeverityFail:
+		return UnknownSeverityPolicy{Action: UnknownSeverityFail}, nil
+	case value == UnknownSeverityIgnore:
+		return UnknownSeverityPolicy{Action: UnknownSeverityIgnore}, nil
+	case strings.HasPrefix(value, UnknownSeverityTreatAs+"-"):
+		severity := ParseSeverity(strings.TrimPrefix(value, UnknownSeverityTreatAs+"-"))
+		if !severity.IsKnown() {
+			return UnknownSeverityPolicy{}, fmt.Errorf("invalid severity in unknown severity policy: %v", policy)
+		}
+		return UnknownSeverityPolicy{Action: UnknownSeverityTreatAs, TreatAs: severity}, nil
+	default:
+		return UnknownSeverityPolicy{}, fmt.Errorf("invalid unknown severity policy: %v", policy)
+	}
+}
+
+// ApplyUnknownSeverityPolicy normalizes the severity of every violation and
+// fails, drops or rewrites the ones with an unknown severity.
+func ApplyUnknownSeverityPolicy(report IACValidationReport, policy UnknownSeverityPolicy) (IACValidationReport, error) {
+	if report.Violations == nil {
+		return report, nil
+	}
+
+	violations := make([]Violation, 0, len(report.Violations))
+
+	for i, violation := range report.Violations {
+		severity := ParseSeverity(violation.Severity)
+		if !severity.IsKnown() {
+			switch {
+			case policy.Action == UnknownSeverityIgnore:
+				continue
+			case policy.Action == UnknownSeverityTreatAs && policy.TreatAs.IsKnown():
+				severity = policy.TreatAs
+			default:
+				return IACValidationReport{}, fmt.Errorf("violation %d of policy %s has unknown severity: %q", i, violation.PolicyID, violation.Severity)
+			}
+		}
+
+		violation.Severity = string(severity)
+		violations = append(violations, violation)
+	}
+
+	report.Violations = violations
+	return report, nil
+}
@@ -0,0 +1,134 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseUnknownSeverityPolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         string
+		expectedPolicy UnknownSeverityPolicy
+		wantErr        bool
+	}{
+		{
+			name:           "EmptyPolicy_Fail",
+			policy:         "",
+			expectedPolicy: UnknownSeverityPolicy{Action: UnknownSeverityFail},
+		},
+		{
+			name:           "IgnorePolicy_Succeeds",
+			policy:         "Ignore",
+			expectedPolicy: UnknownSeverityPolicy{Action: UnknownSeverityIgnore},
+		},
+		{
+			name:           "TreatAsPolicy_Succeeds",
+			policy:         "treat-as-high",
+			expectedPolicy: UnknownSeverityPolicy{Action: UnknownSeverityTreatAs, TreatAs: SeverityHigh},
+		},
+		{
+			name:    "TreatAsUnspecified_Failure",
+			policy:  "treat-as-severity_unspecified",
+			wantErr: true,
+		},
+		{
+			name:    "InvalidPolicy_Failure",
+			policy:  "skip",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := ParseUnknownSeverityPolicy(test.policy)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if diff := cmp.Diff(test.expectedPolicy, policy); diff != "" {
+				t.Errorf("Expected policy (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestApplyUnknownSeverityPolicy(t *testing.T) {
+	report := IACValidationReport{
+		Violations: []Violation{
+			{PolicyID: "policy1", Severity: "high"},
+			{PolicyID: "policy2", Severity: "SEVERITY_UNSPECIFIED"},
+			{PolicyID: "policy3", Severity: "URGENT"},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		policy         UnknownSeverityPolicy
+		expectedReport IACValidationReport
+		wantErr        bool
+	}{
+		{
+			name:    "FailPolicy_Failure",
+			policy:  UnknownSeverityPolicy{Action: UnknownSeverityFail},
+			wantErr: true,
+		},
+		{
+			name:    "ZeroPolicy_Failure",
+			policy:  UnknownSeverityPolicy{},
+			wantErr: true,
+		},
+		{
+			name:   "IgnorePolicy_UnknownDropped",
+			policy: UnknownSeverityPolicy{Action: UnknownSeverityIgnore},
+			expectedReport: IACValidationReport{
+				Violations: []Violation{{PolicyID: "policy1", Severity: "HIGH"}},
+			},
+		},
+		{
+			name:   "TreatAsPolicy_UnknownRewritten",
+			policy: UnknownSeverityPolicy{Action: UnknownSeverityTreatAs, TreatAs: SeverityLow},
+			expectedReport: IACValidationReport{
+				Violations: []Violation{
+					{PolicyID: "policy1", Severity: "HIGH"},
+					{PolicyID: "policy2", Severity: "LOW"},
+					{PolicyID: "policy3", Severity: "LOW"},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := ApplyUnknownSeverityPolicy(report, test.policy)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if diff := cmp.Diff(test.expectedReport, result); diff != "" {
+				t.Errorf("Expected report (+got, -want): %v", diff)
+			}
+		})
+	}
+}
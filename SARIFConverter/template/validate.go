@@ -0,0 +1,92 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationProblem is a structural problem found at a JSON path of the input.
+type ValidationProblem struct {
+	Path    string
+	Message string
+}
+
+func (p ValidationProblem) String() string {
+	return fmt.Sprintf("%s: %s", p.Path, p.Message)
+}
+
+// ValidationError is returned by ParseReportStrict and lists every problem
+// found in the report.
+type ValidationError struct {
+	Problems []ValidationProblem
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Problems))
+	for _, problem := range e.Problems {
+		lines = append(lines, problem.String())
+	}
+	return fmt.Sprintf("report is malformed:\n  %s", strings.Join(lines, "\n  "))
+}
+
+// ParseReportStrict parses the report like ParseReport and then refuses
+// empty reports and violations missing the fields the tools rely on.
+// Unknown severities are only reported when the policy is to fail on them.
+func ParseReportStrict(data []byte, policy UnknownSeverityPolicy) (Responses, error) {
+	report, path, err := parseReport(data)
+	if err != nil {
+		return Responses{}, err
+	}
+
+	if problems := validateReport(report, path, policy); len(problems) > 0 {
+		return Responses{}, &ValidationError{Problems: problems}
+	}
+
+	return report, nil
+}
+
+func validateReport(report Responses, path string, policy UnknownSeverityPolicy) []ValidationProblem {
+	problems := []ValidationProblem{}
+
+	iacReport := report.IacValidationReport
+	if len(iacReport.Violations) == 0 && iacReport.Note == "" && report.Name == "" {
+		problems = append(problems, ValidationProblem{Path: path, Message: "report is empty"})
+	}
+
+	for i, violation := range iacReport.Violations {
+		violationPath := fmt.Sprintf("%s.violations[%d]", path, i)
+
+		if strings.TrimSpace(violation.PolicyID) == "" {
+			problems = append(problems, ValidationProblem{Path: violationPath + ".policyId", Message: "required field is missing or empty"})
+		}
+		if strings.TrimSpace(violation.AssetID) == "" {
+			problems = append(problems, ValidationProblem{Path: violationPath + ".assetId", Message: "required field is missing or empty"})
+		}
+
+		severity := ParseSeverity(violation.Severity)
+		switch {
+		case severity == "":
+			problems = append(problems, ValidationProblem{Path: violationPath + ".severity", Message: "required field is missing or empty"})
+		case !severity.IsKnown() && policy.Fails():
+			problems = append(problems, ValidationProblem{Path: violationPath + ".severity", Message: fmt.Sprintf("unknown severity %q", violation.Severity)})
+		}
+	}
+
+	return problems
+}
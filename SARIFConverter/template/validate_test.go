@@ -0,0 +1,104 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package template
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseReportStrict(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            string
+		policy           UnknownSeverityPolicy
+		expectedProblems []ValidationProblem
+		wantErr          bool
+	}{
+		{
+			name:  "ValidReport_Succeeds",
+			input: `{"response": {"iacValidationReport": {"violations": [{"assetId": "asset1", "policyId": "policy1", "severity": "HIGH"}]}}}`,
+		},
+		{
+			name:  "CleanReport_Succeeds",
+			input: `{"response": {"name": "organizations/1/locations/global/reports/r1", "iacValidationReport": {}}}`,
+		},
+		{
+			name:  "EmptyReport_Failure",
+			input: `{"response": {"iacValidationReport": {"violations": []}}}`,
+			expectedProblems: []ValidationProblem{
+				{Path: "$.response.iacValidationReport", Message: "report is empty"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "MissingFields_Failure",
+			input: `[{"createTime": "2024-05-01T10:00:00Z", "iacValidationReport": {"violations": [
+				{"assetId": "asset1", "policyId": "policy1", "severity": "HIGH"},
+				{"assetId": "", "severity": "URGENT"},
+				{"assetId": "asset3", "policyId": "policy3"}]}}]`,
+			expectedProblems: []ValidationProblem{
+				{Path: "$[0].iacValidationReport.violations[1].policyId", Message: "required field is missing or empty"},
+				{Path: "$[0].iacValidationReport.violations[1].assetId", Message: "required field is missing or empty"},
+				{Path: "$[0].iacValidationReport.violations[1].severity", Message: `unknown severity "URGENT"`},
+				{Path: "$[0].iacValidationReport.violations[2].severity", Message: "required field is missing or empty"},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "UnknownSeverityAllowedByPolicy_Succeeds",
+			input:  `{"violations": [{"assetId": "asset1", "policyId": "policy1", "severity": "SEVERITY_UNSPECIFIED"}]}`,
+			policy: UnknownSeverityPolicy{Action: UnknownSeverityIgnore},
+		},
+		{
+			name:  "SeverityUnspecified_Failure",
+			input: `{"violations": [{"assetId": "asset1", "policyId": "policy1", "severity": "SEVERITY_UNSPECIFIED"}]}`,
+			expectedProblems: []ValidationProblem{
+				{Path: "$.violations[0].severity", Message: `unknown severity "SEVERITY_UNSPECIFIED"`},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "UnrecognizedStructure_Failure",
+			input:   `{"name": "organizations/1/locations/global/operations/op1"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseReportStrict([]byte(test.input), test.policy)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			var problems []ValidationProblem
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) {
+				problems = validationErr.Problems
+			}
+
+			if diff := cmp.Diff(test.expectedProblems, problems); diff != "" {
+				t.Errorf("Expected problems (+got, -want): %v", diff)
+			}
+		})
+	}
+}
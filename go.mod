@@ -2,7 +2,10 @@ module github.com/mikelaramie/IACPlugin2024
 
 go 1.22.2
 
-require github.com/google/go-cmp v0.6.0
+require (
+	github.com/google/go-cmp v0.6.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 //replace github.com/mikelaramie/IACPlugin2024/ReportValidator/fileoperator => ./ReportValidator/fileoperator
 //replace github.com/mikelaramie/IACPlugin2024/ReportValidator/evaluate => ./ReportValidator/evalute
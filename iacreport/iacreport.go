@@ -0,0 +1,177 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package iacreport exposes the SARIF conversion and the validation of SCC IAC
+// validation reports to Go programs, without going through the command line
+// utilities.
+package iacreport
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mikelaramie/IACPlugin2024/ReportValidator/evaluate"
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/converter"
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+// Version is the version of this package API. It follows semantic versioning:
+// breaking changes to the exported identifiers or to the meaning of their
+// inputs bump the major version.
+//
+// 1.1.0 adds the >, >=, ==, <= and < comparators to Policy.Expression, the
+// colon criteria such as "critical:2" keeping their meaning of "critical>=2",
+// and the filter, catalog, ownership, ratio and score options.
+const Version = "1.1.0"
+
+// Report is a normalized SCC IAC validation report.
+type Report = template.Responses
+
+// Options configures ToSARIF.
+type Options struct {
+	// UnknownSeverity handles violations with an unknown severity, the zero
+	// value rejects them.
+	UnknownSeverity template.UnknownSeverityPolicy
+	// SplitRunsByPostureDeployment produces one SARIF run per posture
+	// deployment.
+	SplitRunsByPostureDeployment bool
+	// HelpURITemplates maps constraint types to the help URI of their SARIF
+	// rules, overriding the built-in documentation links.
+	HelpURITemplates map[string]string
+	// Catalog enriches the SARIF rules and results, see posture.LoadCatalog.
+	Catalog template.Catalog
+	// Ownership annotates the SARIF results with the owners of their asset,
+	// see template.LoadOwnership. SourceFiles maps asset IDs to their source
+	// file for its path patterns, see template.LoadFileMapping.
+	Ownership   template.Ownership
+	SourceFiles map[string]string
+	// Filter scopes the violations converted to SARIF.
+	Filter template.ViolationFilter
+	// ScoreWeights, when set, adds the risk score of the violations to the
+	// SARIF run properties.
+	ScoreWeights *template.ScoreWeights
+}
+
+// Policy is the failure criteria a report is evaluated against.
+type Policy struct {
+	// Expression uses the syntax of the validator -expression flag, the
+	// default criteria apply when it is empty.
+	Expression string
+	// UnknownSeverity handles violations with an unknown severity, the zero
+	// value rejects them.
+	UnknownSeverity template.UnknownSeverityPolicy
+	// Filter scopes the violations evaluated against the expression.
+	Filter template.ViolationFilter
+	// Owner, when set, evaluates only the violations on the assets the
+	// Ownership assigns to the owner, SourceFiles resolving its path patterns.
+	Owner       string
+	Ownership   template.Ownership
+	SourceFiles map[string]string
+	// Assets are the assets scanned the ratio criteria of the expression are
+	// relative to. Ratio criteria are rejected with a Filter or an Owner.
+	Assets evaluate.AssetInventory
+	// ScoreWeights weigh the violations for the score criteria of the
+	// expression, the zero value uses template.DefaultScoreWeights.
+	ScoreWeights template.ScoreWeights
+}
+
+// Verdict is the outcome of Evaluate.
+type Verdict struct {
+	Violated bool
+	Trace    evaluate.Trace
+}
+
+// Load reads a report in any of the formats accepted by the command line
+// utilities.
+func Load(r io.Reader) (Report, error) {
+	report, err := template.ReadReport(r)
+	if err != nil {
+		return Report{}, fmt.Errorf("template.ReadReport: %v", err)
+	}
+
+	return report, nil
+}
+
+// LoadStrict reads a report like Load and rejects empty or malformed reports.
+// The returned error is a *template.ValidationError listing every problem.
+func LoadStrict(r io.Reader, policy template.UnknownSeverityPolicy) (Report, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Report{}, fmt.Errorf("io.ReadAll: %v", err)
+	}
+
+	return template.ParseReportStrict(data, policy)
+}
+
+// ToSARIF converts the report to SARIF.
+func ToSARIF(report Report, opts Options) (template.SarifOutput, error) {
+	iacReport, err := template.ApplyUnknownSeverityPolicy(report.IacValidationReport, opts.UnknownSeverity)
+	if err != nil {
+		return template.SarifOutput{}, fmt.Errorf("template.ApplyUnknownSeverityPolicy: %v", err)
+	}
+
+	iacReport.Violations, err = opts.Filter.Apply(iacReport.Violations)
+	if err != nil {
+		return template.SarifOutput{}, fmt.Errorf("opts.Filter.Apply: %v", err)
+	}
+
+	sarifReport, err := converter.FromIACScanReportWithOptions(iacReport, converter.Options{
+		SplitByPostureDeployment: opts.SplitRunsByPostureDeployment,
+		HelpURITemplates:         opts.HelpURITemplates,
+		Catalog:                  opts.Catalog,
+		Ownership:                opts.Ownership,
+		SourceFiles:              opts.SourceFiles,
+		ScoreWeights:             opts.ScoreWeights,
+	})
+	if err != nil {
+		return template.SarifOutput{}, fmt.Errorf("converter.FromIACScanReportWithOptions: %v", err)
+	}
+
+	return sarifReport, nil
+}
+
+// Evaluate checks the report against the failure criteria of the policy.
+func Evaluate(report Report, policy Policy) (Verdict, error) {
+	iacReport, err := template.ApplyUnknownSeverityPolicy(report.IacValidationReport, policy.UnknownSeverity)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("template.ApplyUnknownSeverityPolicy: %v", err)
+	}
+
+	violations, err := policy.Filter.Apply(iacReport.Violations)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("policy.Filter.Apply: %v", err)
+	}
+	if policy.Owner != "" {
+		owned := []template.Violation{}
+		for _, violation := range violations {
+			if policy.Ownership.IsOwnedBy(violation, policy.SourceFiles, policy.Owner) {
+				owned = append(owned, violation)
+			}
+		}
+		violations = owned
+	}
+
+	trace, err := evaluate.ExplainViolations(policy.Expression, violations, evaluate.Context{
+		Assets:       policy.Assets,
+		Scoped:       !policy.Filter.IsEmpty() || policy.Owner != "",
+		ScoreWeights: policy.ScoreWeights,
+	})
+	if err != nil {
+		return Verdict{}, fmt.Errorf("evaluate.ExplainViolations: %v", err)
+	}
+
+	return Verdict{Violated: trace.Violated, Trace: trace}, nil
+}
@@ -0,0 +1,155 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package iacreport
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+const testReport = `{"name": "organizations/1/locations/global/operations/op1", "done": true, "response": {
+	"name": "organizations/1/locations/global/reports/r1",
+	"iacValidationReport": {"violations": [
+		{"assetId": "asset1", "policyId": "policy1", "severity": "HIGH"},
+		{"assetId": "asset2", "policyId": "policy2", "severity": "SEVERITY_UNSPECIFIED"}]}}}`
+
+func TestToSARIF(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            Options
+		expectedResults int
+		wantErr         bool
+	}{
+		{
+			name:    "UnknownSeverityRejected_Failure",
+			wantErr: true,
+		},
+		{
+			name:            "UnknownSeverityIgnored_Succeeds",
+			opts:            Options{UnknownSeverity: template.UnknownSeverityPolicy{Action: template.UnknownSeverityIgnore}},
+			expectedResults: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report, err := Load(strings.NewReader(testReport))
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+
+			sarifReport, err := ToSARIF(report, test.opts)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if err == nil && len(sarifReport.Runs[0].Results) != test.expectedResults {
+				t.Errorf("Expected %d results, got: %d", test.expectedResults, len(sarifReport.Runs[0].Results))
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	treatAsLow := template.UnknownSeverityPolicy{Action: template.UnknownSeverityTreatAs, TreatAs: template.SeverityLow}
+
+	tests := []struct {
+		name             string
+		policy           Policy
+		expectedViolated bool
+		wantErr          bool
+	}{
+		{
+			name:             "DefaultCriteria_Violated",
+			policy:           Policy{UnknownSeverity: treatAsLow},
+			expectedViolated: true,
+		},
+		{
+			name:             "CriticalOnlyCriteria_NotViolated",
+			policy:           Policy{Expression: "critical>=1,operator:or", UnknownSeverity: treatAsLow},
+			expectedViolated: false,
+		},
+		{
+			name: "ExcludedPolicy_NotViolated",
+			policy: Policy{
+				UnknownSeverity: treatAsLow,
+				Filter:          template.ViolationFilter{Exclude: []template.FilterTerm{{Field: template.FILTER_POLICY_ID, Pattern: "policy*"}}},
+			},
+			expectedViolated: false,
+		},
+		{
+			name: "OtherOwner_NotViolated",
+			policy: Policy{
+				UnknownSeverity: treatAsLow,
+				Owner:           "team-b",
+				Ownership:       template.Ownership{Rules: []template.OwnershipRule{{Pattern: "asset*", Owners: []string{"team-a"}}}},
+			},
+			expectedViolated: false,
+		},
+		{
+			name:    "InvalidExpression_Failure",
+			policy:  Policy{Expression: "critical>=1", UnknownSeverity: treatAsLow},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report, err := Load(strings.NewReader(testReport))
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+
+			verdict, err := Evaluate(report, test.policy)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if verdict.Violated != test.expectedViolated {
+				t.Errorf("Unexpected verdict want: %v, got: %v", test.expectedViolated, verdict.Violated)
+			}
+		})
+	}
+}
+
+func TestLoadStrict(t *testing.T) {
+	if _, err := LoadStrict(strings.NewReader(`{"violations": []}`), template.UnknownSeverityPolicy{}); err == nil {
+		t.Errorf("Expected error for an empty report, got nil")
+	}
+}
+
+func TestToSARIF_Ownership(t *testing.T) {
+	report, err := Load(strings.NewReader(testReport))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	sarifReport, err := ToSARIF(report, Options{
+		UnknownSeverity: template.UnknownSeverityPolicy{Action: template.UnknownSeverityIgnore},
+		Ownership:       template.Ownership{Rules: []template.OwnershipRule{{Pattern: "path:storage/", Owners: []string{"team-a"}}}},
+		SourceFiles:     map[string]string{"asset1": "storage/main.tf"},
+	})
+	if err != nil {
+		t.Fatalf("ToSARIF: %v", err)
+	}
+
+	if owners := sarifReport.Runs[0].Results[0].Properties.AssetOwners; len(owners) != 1 || owners[0] != "team-a" {
+		t.Errorf("Expected the result owned by team-a, got: %v", owners)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package posture
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+// Catalog returns the catalog entries of the policies of the posture, matched
+// by policy ID and constraint name and enriched by their annotations.
+func (p Posture) Catalog() template.Catalog {
+	catalog := template.Catalog{}
+	for _, policySet := range p.PolicySets {
+		for _, policy := range policySet.Policies {
+			catalogPolicy := template.CatalogPolicy{
+				PolicyID:   policy.PolicyID,
+				Constraint: policy.Constraint.Name(),
+				Owner:      policy.Annotations[ANNOTATION_OWNER],
+				RunbookURL: policy.Annotations[ANNOTATION_RUNBOOK_URL],
+				RiskRating: policy.Annotations[ANNOTATION_SEVERITY],
+			}
+			if tags := policy.Annotations[ANNOTATION_TAGS]; tags != "" {
+				for _, tag := range strings.Split(tags, ",") {
+					catalogPolicy.Tags = append(catalogPolicy.Tags, strings.TrimSpace(tag))
+				}
+			}
+			catalog.Policies = append(catalog.Policies, catalogPolicy)
+		}
+	}
+
+	return catalog
+}
+
+// ParseCatalog parses a policy library, see template.ParseCatalog, or a
+// posture YAML file, see Posture.Catalog.
+func ParseCatalog(data []byte) (template.Catalog, error) {
+	var keys map[string]interface{}
+	if err := yaml.Unmarshal(data, &keys); err != nil {
+		return template.Catalog{}, fmt.Errorf("yaml.Unmarshal(): %v", err)
+	}
+	if _, ok := keys["policySets"]; !ok {
+		return template.ParseCatalog(data)
+	}
+
+	// The policies are only matched, not evaluated: their conditions are not
+	// checked.
+	posture, err := decode(data)
+	if err != nil {
+		return template.Catalog{}, err
+	}
+
+	catalog := posture.Catalog()
+	if err := catalog.Validate(); err != nil {
+		return template.Catalog{}, err
+	}
+
+	return catalog, nil
+}
+
+// LoadCatalog reads a policy library or posture from a JSON or YAML file, or
+// from every .json, .yaml and .yml file of a directory.
+func LoadCatalog(path string) (template.Catalog, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return template.Catalog{}, fmt.Errorf("os.Stat(%s): %v", path, err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return template.Catalog{}, fmt.Errorf("os.ReadDir(%s): %v", path, err)
+		}
+		for _, entry := range entries {
+			switch filepath.Ext(entry.Name()) {
+			case ".json", ".yaml", ".yml":
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+
+	catalog := template.Catalog{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return template.Catalog{}, fmt.Errorf("os.ReadFile(%s): %v", file, err)
+		}
+
+		fileCatalog, err := ParseCatalog(data)
+		if err != nil {
+			return template.Catalog{}, fmt.Errorf("ParseCatalog(%s): %v", file, err)
+		}
+		catalog.Policies = append(catalog.Policies, fileCatalog.Policies...)
+	}
+
+	return catalog, nil
+}
@@ -0,0 +1,127 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package posture
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+const catalogPosture = `
+name: organizations/123/locations/global/postures/posture1
+state: ACTIVE
+policySets:
+- policySetId: storage
+  policies:
+  - policyId: uniform_bucket_level_access
+    constraint:
+      orgPolicyConstraint:
+        cannedConstraintId: storage.uniformBucketLevelAccess
+    annotations:
+      owner: storage-team
+      severity: critical
+      tags: pci, storage
+  - policyId: bucket_logging
+    constraint:
+      securityHealthAnalyticsModule:
+        moduleName: BUCKET_LOGGING_DISABLED
+  - policyId: labels
+    constraint:
+      orgPolicyConstraintCustom:
+        customConstraint:
+          name: organizations/123/customConstraints/custom.labels
+          condition: resource.labels.all(k, k.startsWith('team-'))
+          actionType: ALLOW
+`
+
+func TestParseCatalog(t *testing.T) {
+	tests := []struct {
+		name            string
+		data            string
+		expectedCatalog template.Catalog
+		wantErr         bool
+	}{
+		{
+			name: "Posture_Succeeds",
+			data: catalogPosture,
+			expectedCatalog: template.Catalog{Policies: []template.CatalogPolicy{
+				{PolicyID: "uniform_bucket_level_access", Constraint: "storage.uniformBucketLevelAccess", Owner: "storage-team", RiskRating: "critical", Tags: []string{"pci", "storage"}},
+				{PolicyID: "bucket_logging", Constraint: "BUCKET_LOGGING_DISABLED"},
+				{PolicyID: "labels", Constraint: "organizations/123/customConstraints/custom.labels"},
+			}},
+		},
+		{
+			name: "Library_Succeeds",
+			data: `{"policies": [{"policyId": "P1", "owner": "team-a"}]}`,
+			expectedCatalog: template.Catalog{Policies: []template.CatalogPolicy{
+				{PolicyID: "P1", Owner: "team-a"},
+			}},
+		},
+		{
+			name: "PostureUnknownSeverity_Failure",
+			data: `
+policySets:
+- policies:
+  - policyId: P1
+    annotations:
+      severity: SEVERE
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			catalog, err := ParseCatalog([]byte(test.data))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if diff := cmp.Diff(test.expectedCatalog, catalog); diff != "" {
+				t.Errorf("Expected catalog (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestLoadCatalog_Directory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"library.json": `{"policies": [{"policyId": "P1", "owner": "team-a"}]}`,
+		"posture.yaml": catalogPosture,
+		"README.md":    "not a catalog",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("os.WriteFile: %v", err)
+		}
+	}
+
+	catalog, err := LoadCatalog(dir)
+	if err != nil {
+		t.Fatalf("LoadCatalog: %v", err)
+	}
+
+	if len(catalog.Policies) != 4 {
+		t.Errorf("Expected 4 policies, got: %v", catalog.Policies)
+	}
+}
@@ -0,0 +1,798 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package posture
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Condition is a custom constraint condition, written in the subset of CEL
+// the offline evaluation supports:
+//   - field selections and indexes on resource, e.g. resource.labels['env'];
+//   - string literals, with the CEL escape sequences or raw as in r'\d',
+//     number, boolean, null and list literals;
+//   - the !, &&, ||, ==, !=, <, <=, >, >= and in operators;
+//   - the has() macro, size() and the startsWith, endsWith, contains and
+//     matches string functions.
+//
+// Fields are looked up by their name in the API and else in snake case, and
+// single-element lists are transparent, so conditions written against the
+// API also read the Terraform attributes of the plan. Missing fields are null.
+type Condition struct {
+	root node
+}
+
+// ParseCondition parses a custom constraint condition.
+func ParseCondition(src string) (Condition, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return Condition{}, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return Condition{}, err
+	}
+	if p.pos < len(p.tokens) {
+		return Condition{}, fmt.Errorf("unexpected %q at offset %d", p.tokens[p.pos].text, p.tokens[p.pos].offset)
+	}
+
+	return Condition{root: root}, nil
+}
+
+// Eval evaluates the condition on the resource data.
+func (c Condition) Eval(resource map[string]interface{}) (bool, error) {
+	if c.root == nil {
+		return false, fmt.Errorf("empty condition")
+	}
+
+	value, err := c.root.eval(resource)
+	if err != nil {
+		return false, err
+	}
+
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("condition evaluates to %T, expected a boolean", value)
+	}
+
+	return result, nil
+}
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenString
+	tokenNumber
+	tokenPunct
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+// puncts are matched in order, the two-character ones first.
+var puncts = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ".", ",", "-"}
+
+func tokenize(src string) ([]token, error) {
+	tokens := []token{}
+
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '\'' || c == '"':
+			text, next, err := scanString(src, i, false)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, offset: i})
+			i = next
+		case (c == 'r' || c == 'R') && i+1 < len(src) && (src[i+1] == '\'' || src[i+1] == '"'):
+			text, next, err := scanString(src, i+1, true)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, offset: i})
+			i = next
+		case unicode.IsDigit(c):
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: src[start:i], offset: start})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(src) && (unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i])) || src[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: src[start:i], offset: start})
+		default:
+			matched := false
+			for _, punct := range puncts {
+				if strings.HasPrefix(src[i:], punct) {
+					tokens = append(tokens, token{kind: tokenPunct, text: punct, offset: i})
+					i += len(punct)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+			}
+		}
+	}
+
+	return tokens, nil
+}
+
+// celEscapes are the single-character escape sequences of CEL strings.
+var celEscapes = map[byte]string{
+	'\\': "\\", '\'': "'", '"': "\"", '`': "`", '?': "?",
+	'a': "\a", 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t", 'v': "\v",
+}
+
+// scanString reads the string literal whose quote is at offset start and
+// returns its value and the offset following it. The escape sequences of CEL
+// are decoded unless the string is raw, e.g. r'\d'.
+func scanString(src string, start int, raw bool) (string, int, error) {
+	quote := src[start]
+
+	var b strings.Builder
+	for i := start + 1; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\\' && !raw:
+			if i+1 == len(src) {
+				return "", 0, fmt.Errorf("unterminated string at offset %d", start)
+			}
+			i++
+			if escaped, ok := celEscapes[src[i]]; ok {
+				b.WriteString(escaped)
+				continue
+			}
+
+			var digits, base int
+			switch src[i] {
+			case 'x', 'X':
+				digits, base = 2, 16
+			case 'u':
+				digits, base = 4, 16
+			case 'U':
+				digits, base = 8, 16
+			case '0', '1', '2', '3':
+				digits, base = 3, 8
+				i--
+			default:
+				return "", 0, fmt.Errorf("invalid escape sequence \\%c at offset %d", src[i], i-1)
+			}
+			if i+digits >= len(src) {
+				return "", 0, fmt.Errorf("invalid escape sequence at offset %d", i-1)
+			}
+			code, err := strconv.ParseUint(src[i+1:i+1+digits], base, 32)
+			if err != nil {
+				return "", 0, fmt.Errorf("invalid escape sequence at offset %d: %v", i-1, err)
+			}
+			if base == 16 && digits == 2 || base == 8 {
+				b.WriteByte(byte(code))
+			} else {
+				b.WriteRune(rune(code))
+			}
+			i += digits
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated string at offset %d", start)
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// peek reports whether the next token is the punctuation or the in keyword.
+func (p *parser) peek(text string) bool {
+	if p.pos >= len(p.tokens) {
+		return false
+	}
+	t := p.tokens[p.pos]
+
+	return t.text == text && (t.kind == tokenPunct || t.kind == tokenIdent && text == "in")
+}
+
+func (p *parser) expect(text string) error {
+	if !p.peek(text) {
+		if p.pos < len(p.tokens) {
+			return fmt.Errorf("expected %q at offset %d, got %q", text, p.tokens[p.pos].offset, p.tokens[p.pos].text)
+		}
+		return fmt.Errorf("expected %q, got the end of the condition", text)
+	}
+	p.pos++
+
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek("||") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: "||", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseRelation()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek("&&") {
+		p.pos++
+		right, err := p.parseRelation()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: "&&", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseRelation() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">", "in"} {
+		if p.peek(op) {
+			p.pos++
+			right, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			return binaryNode{op: op, left: left, right: right}, nil
+		}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	for _, op := range []string{"!", "-"} {
+		if p.peek(op) {
+			p.pos++
+			operand, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			return unaryNode{op: op, operand: operand}, nil
+		}
+	}
+
+	return p.parseMember()
+}
+
+func (p *parser) parseMember() (node, error) {
+	operand, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.peek("."):
+			p.pos++
+			if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokenIdent {
+				return nil, fmt.Errorf("expected a field name after '.'")
+			}
+			name := p.tokens[p.pos].text
+			p.pos++
+			if p.peek("(") {
+				args, err := p.parseArgs()
+				if err != nil {
+					return nil, err
+				}
+				operand = callNode{target: operand, name: name, args: args}
+			} else {
+				operand = selectNode{operand: operand, field: name}
+			}
+		case p.peek("["):
+			p.pos++
+			index, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			operand = indexNode{operand: operand, index: index}
+		default:
+			return operand, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of the condition")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+
+	switch t.kind {
+	case tokenString:
+		return literalNode{value: t.text}, nil
+	case tokenNumber:
+		number, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at offset %d", t.text, t.offset)
+		}
+		return literalNode{value: number}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true", "false":
+			return literalNode{value: t.text == "true"}, nil
+		case "null":
+			return literalNode{value: nil}, nil
+		}
+		if p.peek("(") {
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			return callNode{name: t.text, args: args}, nil
+		}
+		if t.text != "resource" {
+			return nil, fmt.Errorf("undeclared reference to %q at offset %d", t.text, t.offset)
+		}
+		return identNode{}, nil
+	}
+
+	switch t.text {
+	case "(":
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(")")
+	case "[":
+		list := listNode{}
+		for !p.peek("]") {
+			element, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			list.elements = append(list.elements, element)
+			if !p.peek(",") {
+				break
+			}
+			p.pos++
+		}
+		return list, p.expect("]")
+	}
+
+	return nil, fmt.Errorf("unexpected %q at offset %d", t.text, t.offset)
+}
+
+func (p *parser) parseArgs() ([]node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	args := []node{}
+	for !p.peek(")") {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if !p.peek(",") {
+			break
+		}
+		p.pos++
+	}
+
+	return args, p.expect(")")
+}
+
+type node interface {
+	eval(resource map[string]interface{}) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n literalNode) eval(map[string]interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+// identNode is the resource, the only variable of the conditions.
+type identNode struct{}
+
+func (n identNode) eval(resource map[string]interface{}) (interface{}, error) {
+	return resource, nil
+}
+
+type listNode struct {
+	elements []node
+}
+
+func (n listNode) eval(resource map[string]interface{}) (interface{}, error) {
+	list := make([]interface{}, 0, len(n.elements))
+	for _, element := range n.elements {
+		value, err := element.eval(resource)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+
+	return list, nil
+}
+
+type selectNode struct {
+	operand node
+	field   string
+}
+
+func (n selectNode) eval(resource map[string]interface{}) (interface{}, error) {
+	operand, err := n.operand.eval(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	value, _ := lookup(operand, n.field)
+	return value, nil
+}
+
+// lookup returns the field of a map, by its name or in snake case, looking
+// through the single-element lists of Terraform blocks. terraform show -json
+// writes the unset attributes as null and the unset blocks as empty lists, so
+// these are absent like the missing fields.
+func lookup(operand interface{}, field string) (interface{}, bool) {
+	if list, ok := operand.([]interface{}); ok && len(list) == 1 {
+		operand = list[0]
+	}
+
+	fields, ok := operand.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	if value := fields[field]; !isAbsent(value) {
+		return value, true
+	}
+	if value := fields[snakeCase(field)]; !isAbsent(value) {
+		return value, true
+	}
+
+	return nil, false
+}
+
+func isAbsent(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+
+	return false
+}
+
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, c := range name {
+		if unicode.IsUpper(c) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			c = unicode.ToLower(c)
+		}
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
+
+type indexNode struct {
+	operand node
+	index   node
+}
+
+func (n indexNode) eval(resource map[string]interface{}) (interface{}, error) {
+	operand, err := n.operand.eval(resource)
+	if err != nil {
+		return nil, err
+	}
+	index, err := n.index.eval(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := index.(type) {
+	case string:
+		value, _ := lookup(operand, key)
+		return value, nil
+	case float64:
+		list, ok := operand.([]interface{})
+		if !ok || key < 0 || int(key) >= len(list) {
+			return nil, nil
+		}
+		return list[int(key)], nil
+	default:
+		return nil, fmt.Errorf("invalid index %v", index)
+	}
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n unaryNode) eval(resource map[string]interface{}) (interface{}, error) {
+	operand, err := n.operand.eval(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	switch value := operand.(type) {
+	case bool:
+		if n.op == "!" {
+			return !value, nil
+		}
+	case float64:
+		if n.op == "-" {
+			return -value, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no such overload: %s%T", n.op, operand)
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n binaryNode) eval(resource map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "&&" || n.op == "||" {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("no such overload: %T %s", left, n.op)
+		}
+		if l == (n.op == "||") {
+			return l, nil
+		}
+		right, err := n.right.eval(resource)
+		if err != nil {
+			return nil, err
+		}
+		r, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("no such overload: %s %T", n.op, right)
+		}
+		return r, nil
+	}
+
+	right, err := n.right.eval(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		switch container := right.(type) {
+		case []interface{}:
+			for _, element := range container {
+				if equal(left, element) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case map[string]interface{}:
+			key, ok := left.(string)
+			_, found := container[key]
+			return ok && found, nil
+		default:
+			return false, nil
+		}
+	}
+
+	return compare(n.op, left, right)
+}
+
+func equal(left, right interface{}) bool {
+	return reflect.DeepEqual(normalize(left), normalize(right))
+}
+
+// normalize converts the numbers decoded from YAML or JSON to float64.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case []interface{}:
+		normalized := make([]interface{}, len(v))
+		for i, element := range v {
+			normalized[i] = normalize(element)
+		}
+		return normalized
+	default:
+		return value
+	}
+}
+
+func compare(op string, left, right interface{}) (interface{}, error) {
+	var cmp int
+	switch l := normalize(left).(type) {
+	case float64:
+		r, ok := normalize(right).(float64)
+		if !ok {
+			return nil, fmt.Errorf("no such overload: number %s %T", op, right)
+		}
+		switch {
+		case l < r:
+			cmp = -1
+		case l > r:
+			cmp = 1
+		}
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("no such overload: string %s %T", op, right)
+		}
+		cmp = strings.Compare(l, r)
+	default:
+		return nil, fmt.Errorf("no such overload: %T %s %T", left, op, right)
+	}
+
+	switch op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+type callNode struct {
+	target node
+	name   string
+	args   []node
+}
+
+func (n callNode) eval(resource map[string]interface{}) (interface{}, error) {
+	if n.target == nil {
+		switch {
+		case n.name == "has" && len(n.args) == 1:
+			field, ok := n.args[0].(selectNode)
+			if !ok {
+				return nil, fmt.Errorf("has() expects a field selection")
+			}
+			operand, err := field.operand.eval(resource)
+			if err != nil {
+				return nil, err
+			}
+			_, found := lookup(operand, field.field)
+			return found, nil
+		case n.name == "size" && len(n.args) == 1:
+			value, err := n.args[0].eval(resource)
+			if err != nil {
+				return nil, err
+			}
+			return size(value)
+		}
+		return nil, fmt.Errorf("unsupported function %s/%d", n.name, len(n.args))
+	}
+
+	target, err := n.target.eval(resource)
+	if err != nil {
+		return nil, err
+	}
+	if n.name == "size" && len(n.args) == 0 {
+		return size(target)
+	}
+
+	if len(n.args) != 1 {
+		return nil, fmt.Errorf("unsupported function %s/%d", n.name, len(n.args))
+	}
+	arg, err := n.args[0].eval(resource)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := target.(string)
+	if !ok {
+		if target == nil {
+			return false, nil
+		}
+		return nil, fmt.Errorf("no such overload: %T.%s", target, n.name)
+	}
+	a, ok := arg.(string)
+	if !ok {
+		return nil, fmt.Errorf("no such overload: string.%s(%T)", n.name, arg)
+	}
+
+	switch n.name {
+	case "startsWith":
+		return strings.HasPrefix(s, a), nil
+	case "endsWith":
+		return strings.HasSuffix(s, a), nil
+	case "contains":
+		return strings.Contains(s, a), nil
+	case "matches":
+		re, err := regexp.Compile(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %v", a, err)
+		}
+		return re.MatchString(s), nil
+	}
+
+	return nil, fmt.Errorf("unsupported function %s", n.name)
+}
+
+func size(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return float64(0), nil
+	case string:
+		return float64(len(v)), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	}
+
+	return nil, fmt.Errorf("no such overload: size(%T)", value)
+}
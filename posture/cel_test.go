@@ -0,0 +1,176 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package posture
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConditionEval(t *testing.T) {
+	resource := map[string]interface{}{
+		"name":                        "logs",
+		"location":                    "EU",
+		"uniform_bucket_level_access": true,
+		"labels":                      map[string]interface{}{"env": "prod"},
+		"versioning":                  []interface{}{map[string]interface{}{"enabled": false}},
+		"cors":                        []interface{}{"a", "b"},
+		"retention_days":              float64(30),
+	}
+
+	tests := []struct {
+		name      string
+		condition string
+		expected  bool
+		wantErr   bool
+	}{
+		{name: "Equality_Succeeds", condition: "resource.location == 'EU'", expected: true},
+		{name: "CamelCaseField_Succeeds", condition: "resource.uniformBucketLevelAccess == true", expected: true},
+		{name: "SingleElementBlock_Succeeds", condition: "resource.versioning.enabled", expected: false},
+		{name: "Index_Succeeds", condition: "resource.labels['env'] != 'dev'", expected: true},
+		{name: "In_Succeeds", condition: "resource.location in ['EU', 'US']", expected: true},
+		{name: "AndOrNot_Succeeds", condition: "!(resource.name == 'x') && (false || resource.retention_days >= 30)", expected: true},
+		{name: "Has_Succeeds", condition: "has(resource.labels) && !has(resource.encryption)", expected: true},
+		{name: "Size_Succeeds", condition: "size(resource.cors) == 2 && resource.name.size() < 5", expected: true},
+		{name: "StringFunctions_Succeeds", condition: "resource.name.startsWith('lo') && resource.name.endsWith('gs') && resource.name.contains('og')", expected: true},
+		{name: "Matches_Succeeds", condition: "resource.name.matches('^[a-z]+$')", expected: true},
+		{name: "MissingField_Succeeds", condition: "resource.encryption == null", expected: true},
+		{name: "NotBoolean_Failure", condition: "resource.name", wantErr: true},
+		{name: "UnsupportedFunction_Failure", condition: "resource.name.lowerAscii() == 'logs'", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			condition, err := ParseCondition(test.condition)
+			if err != nil {
+				t.Fatalf("ParseCondition(%q): %v", test.condition, err)
+			}
+
+			result, err := condition.Eval(resource)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+			if result != test.expected {
+				t.Errorf("Expected %q to evaluate to %v, got: %v", test.condition, test.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseCondition_Failure(t *testing.T) {
+	for _, condition := range []string{
+		"",
+		"resource.name ==",
+		"resource.name == 'logs",
+		"(resource.name == 'logs'",
+		"request.name == 'logs'",
+		"resource.name = 'logs'",
+		`resource.name == 'lo\qs'`,
+		`resource.name == 'logs\'`,
+		`resource.name == '\x6'`,
+	} {
+		if _, err := ParseCondition(condition); err == nil {
+			t.Errorf("Expected an error parsing %q", condition)
+		}
+	}
+}
+
+func TestConditionEval_UnsetValues(t *testing.T) {
+	// The values of a google_storage_bucket in terraform show -json, which
+	// writes the unset attributes as null and the unset blocks as empty lists.
+	var resource map[string]interface{}
+	values := `{
+		"name": "logs",
+		"location": "EU",
+		"default_event_based_hold": null,
+		"encryption": [],
+		"labels": null,
+		"retention_policy": [],
+		"versioning": [{"enabled": true}],
+		"website": [{}]
+	}`
+	if err := json.Unmarshal([]byte(values), &resource); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		condition string
+		expected  bool
+	}{
+		{name: "NullAttribute_Succeeds", condition: "!has(resource.defaultEventBasedHold)", expected: true},
+		{name: "EmptyBlock_Succeeds", condition: "!has(resource.encryption) && !has(resource.retention_policy)", expected: true},
+		{name: "EmptyNestedBlock_Succeeds", condition: "!has(resource.website.main_page_suffix)", expected: true},
+		{name: "NullEqualsNull_Succeeds", condition: "resource.labels == null && resource.encryption == null", expected: true},
+		{name: "SetBlock_Succeeds", condition: "has(resource.versioning) && resource.versioning.enabled", expected: true},
+		{name: "SizeOfEmptyBlock_Succeeds", condition: "size(resource.encryption) == 0", expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			condition, err := ParseCondition(test.condition)
+			if err != nil {
+				t.Fatalf("ParseCondition(%q): %v", test.condition, err)
+			}
+
+			result, err := condition.Eval(resource)
+			if err != nil {
+				t.Fatalf("Eval(%q): %v", test.condition, err)
+			}
+			if result != test.expected {
+				t.Errorf("Expected %q to evaluate to %v, got: %v", test.condition, test.expected, result)
+			}
+		})
+	}
+}
+
+func TestConditionEval_StringLiterals(t *testing.T) {
+	resource := map[string]interface{}{
+		"name":        "vm1",
+		"description": "it's a \"test\"\nvm",
+		"path":        `a\b`,
+	}
+
+	tests := []struct {
+		name      string
+		condition string
+	}{
+		{name: "EscapedDigitClass", condition: `resource.name.matches('^[a-z]+\\d$')`},
+		{name: "RawString", condition: `resource.name.matches(r'^[a-z]+\d$')`},
+		{name: "EscapedQuotes", condition: `resource.description.startsWith('it\'s a "test"')`},
+		{name: "EscapedNewline", condition: `resource.description.endsWith("\"\nvm")`},
+		{name: "EscapedBackslash", condition: `resource.path == 'a\\b' && resource.path == R"a\b"`},
+		{name: "NumericEscapes", condition: `resource.name == '\x76\155\u0031'`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			condition, err := ParseCondition(test.condition)
+			if err != nil {
+				t.Fatalf("ParseCondition(%q): %v", test.condition, err)
+			}
+
+			result, err := condition.Eval(resource)
+			if err != nil {
+				t.Fatalf("Eval(%q): %v", test.condition, err)
+			}
+			if !result {
+				t.Errorf("Expected %q to evaluate to true", test.condition)
+			}
+		})
+	}
+}
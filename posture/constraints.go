@@ -0,0 +1,178 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package posture
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mikelaramie/IACPlugin2024/tfplan"
+)
+
+// cannedConstraint evaluates a predefined org policy constraint against the
+// Terraform attributes of the planned assets of its types.
+type cannedConstraint struct {
+	// assetTypes are the asset types the constraint applies to, every asset
+	// type when empty.
+	assetTypes []string
+	// violates reports whether the asset violates the rules of the policy.
+	violates  func(asset tfplan.Asset, rules []PolicyRule) bool
+	nextSteps string
+}
+
+// cannedConstraints are the predefined constraints evaluated offline, the
+// other ones being reported as skipped.
+var cannedConstraints = map[string]cannedConstraint{
+	"storage.uniformBucketLevelAccess": {
+		assetTypes: []string{"storage.googleapis.com/Bucket"},
+		violates: whenEnforced(func(data map[string]interface{}) bool {
+			return attribute(data, "uniform_bucket_level_access") != true
+		}),
+		nextSteps: "Set uniform_bucket_level_access to true on the bucket.",
+	},
+	"storage.publicAccessPrevention": {
+		assetTypes: []string{"storage.googleapis.com/Bucket"},
+		violates: whenEnforced(func(data map[string]interface{}) bool {
+			return attribute(data, "public_access_prevention") != "enforced"
+		}),
+		nextSteps: "Set public_access_prevention to \"enforced\" on the bucket.",
+	},
+	"compute.skipDefaultNetworkCreation": {
+		assetTypes: []string{"cloudresourcemanager.googleapis.com/Project"},
+		violates: whenEnforced(func(data map[string]interface{}) bool {
+			return attribute(data, "auto_create_network") != false
+		}),
+		nextSteps: "Set auto_create_network to false on the project.",
+	},
+	"compute.requireOsLogin": {
+		assetTypes: []string{"compute.googleapis.com/Instance"},
+		violates: whenEnforced(func(data map[string]interface{}) bool {
+			return !strings.EqualFold(fmt.Sprint(attribute(data, "metadata", "enable-oslogin")), "true")
+		}),
+		nextSteps: "Set the enable-oslogin metadata of the instance to TRUE.",
+	},
+	"compute.disableSerialPortAccess": {
+		assetTypes: []string{"compute.googleapis.com/Instance"},
+		violates: whenEnforced(func(data map[string]interface{}) bool {
+			enabled := fmt.Sprint(attribute(data, "metadata", "serial-port-enable"))
+			return strings.EqualFold(enabled, "true") || enabled == "1"
+		}),
+		nextSteps: "Remove the serial-port-enable metadata of the instance.",
+	},
+	"sql.restrictPublicIp": {
+		assetTypes: []string{"sqladmin.googleapis.com/Instance"},
+		violates: whenEnforced(func(data map[string]interface{}) bool {
+			return attribute(data, "settings", "ip_configuration", "ipv4_enabled") != false
+		}),
+		nextSteps: "Set settings.ip_configuration.ipv4_enabled to false on the instance.",
+	},
+	"sql.restrictAuthorizedNetworks": {
+		assetTypes: []string{"sqladmin.googleapis.com/Instance"},
+		violates: whenEnforced(func(data map[string]interface{}) bool {
+			networks, _ := attribute(data, "settings", "ip_configuration", "authorized_networks").([]interface{})
+			return len(networks) > 0
+		}),
+		nextSteps: "Remove the authorized_networks of the instance.",
+	},
+	"compute.vmExternalIpAccess": {
+		assetTypes: []string{"compute.googleapis.com/Instance"},
+		violates: func(asset tfplan.Asset, rules []PolicyRule) bool {
+			interfaces, _ := asset.Resource.Data["network_interface"].([]interface{})
+			for _, networkInterface := range interfaces {
+				if accessConfigs, _ := attribute(networkInterface, "access_config").([]interface{}); len(accessConfigs) > 0 {
+					return !listAllows(rules, strings.TrimPrefix(asset.Name, "//compute.googleapis.com/"))
+				}
+			}
+			return false
+		},
+		nextSteps: "Remove the access_config of the network interfaces of the instance.",
+	},
+	"gcp.resourceLocations": {
+		violates: func(asset tfplan.Asset, rules []PolicyRule) bool {
+			return asset.Resource.Location != "" && !listAllows(rules, asset.Resource.Location)
+		},
+		nextSteps: "Create the resource in one of the locations allowed by the policy.",
+	},
+}
+
+func whenEnforced(violates func(data map[string]interface{}) bool) func(tfplan.Asset, []PolicyRule) bool {
+	return func(asset tfplan.Asset, rules []PolicyRule) bool {
+		return enforced(rules) && violates(asset.Resource.Data)
+	}
+}
+
+// attribute returns the nested attribute of the Terraform values, looking
+// through the single-element lists of blocks.
+func attribute(values interface{}, path ...string) interface{} {
+	for _, field := range path {
+		values, _ = lookup(values, field)
+	}
+
+	return values
+}
+
+// listAllows reports whether the rules of a list constraint allow the value:
+// it must not be denied and, when the rules restrict the allowed values, be
+// one of them.
+func listAllows(rules []PolicyRule, value string) bool {
+	restricted := false
+	for _, rule := range rules {
+		if rule.DenyAll || rule.Values != nil && matchesAny(rule.Values.DeniedValues, value) {
+			return false
+		}
+		restricted = restricted || rule.Values != nil && len(rule.Values.AllowedValues) > 0
+	}
+
+	for _, rule := range rules {
+		if rule.AllowAll || rule.Values != nil && matchesAny(rule.Values.AllowedValues, value) {
+			return true
+		}
+	}
+
+	return !restricted
+}
+
+// locationGroupPrefixes are the region prefixes of the in:<group>-locations
+// value groups, when they differ from the group name.
+var locationGroupPrefixes = map[string]string{"eu": "europe-"}
+
+// matchesAny reports whether the value is one of the policy values. Values
+// may be prefixed with under: or is:, and the in:<group>-locations value
+// groups match the multi-region and the regions of the group.
+func matchesAny(policyValues []string, value string) bool {
+	for _, policyValue := range policyValues {
+		policyValue = strings.TrimPrefix(strings.TrimPrefix(policyValue, "is:"), "under:")
+
+		if group, ok := strings.CutPrefix(policyValue, "in:"); ok {
+			group = strings.TrimSuffix(group, "-locations")
+			prefix, ok := locationGroupPrefixes[group]
+			if !ok {
+				prefix = group + "-"
+			}
+			if strings.EqualFold(value, group) || strings.HasPrefix(strings.ToLower(value), prefix) {
+				return true
+			}
+			continue
+		}
+
+		if strings.EqualFold(policyValue, value) {
+			return true
+		}
+	}
+
+	return false
+}
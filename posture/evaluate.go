@@ -0,0 +1,147 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package posture
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+	"github.com/mikelaramie/IACPlugin2024/tfplan"
+)
+
+// DEFAULT_SEVERITY is the severity of the violations of the policies without
+// a severity annotation.
+const DEFAULT_SEVERITY = template.SeverityMedium
+
+// Evaluate checks the planned assets against the org policies of the posture
+// and returns the violations in the shape of an SCC IaC validation report.
+// Policies that can not be evaluated offline, Security Health Analytics
+// modules and unsupported predefined constraints, are listed in the note of
+// the report, as are custom constraint conditions failing on an asset.
+func Evaluate(posture Posture, assets []tfplan.Asset) (template.IACValidationReport, error) {
+	report := template.IACValidationReport{}
+	skipped := []string{}
+
+	for _, policySet := range posture.PolicySets {
+		for _, policy := range policySet.Policies {
+			severity := DEFAULT_SEVERITY
+			if annotation := policy.Annotations[ANNOTATION_SEVERITY]; annotation != "" {
+				severity = template.ParseSeverity(annotation)
+				if !severity.IsKnown() {
+					return template.IACValidationReport{}, fmt.Errorf("policy %s: unknown severity %q", policy.PolicyID, annotation)
+				}
+			}
+
+			violation := template.Violation{
+				PolicyID: policy.PolicyID,
+				ViolatedPosture: template.PostureDetails{
+					Posture:           posture.Name,
+					PostureRevisionID: posture.RevisionID,
+					PolicySet:         policySet.PolicySetID,
+				},
+				ViolatedPolicy: template.PolicyDetails{
+					ComplianceStandards: complianceStandards(policy.ComplianceStandards),
+					Description:         policy.Description,
+				},
+				Severity: string(severity),
+			}
+
+			var violated []tfplan.Asset
+			switch constraint := policy.Constraint; {
+			case constraint.OrgPolicyConstraint != nil:
+				canned, ok := cannedConstraints[constraint.OrgPolicyConstraint.CannedConstraintID]
+				if !ok {
+					skipped = append(skipped, fmt.Sprintf("%s (constraint %s is not supported offline)", policy.PolicyID, constraint.OrgPolicyConstraint.CannedConstraintID))
+					continue
+				}
+				violation.ViolatedPolicy.Constraint = constraint.OrgPolicyConstraint.CannedConstraintID
+				violation.ViolatedPolicy.ConstraintType = CONSTRAINT_TYPE_ORG_POLICY
+				violation.NextSteps = canned.nextSteps
+
+				for _, asset := range assets {
+					if (len(canned.assetTypes) == 0 || slices.Contains(canned.assetTypes, asset.AssetType)) &&
+						canned.violates(asset, constraint.OrgPolicyConstraint.PolicyRules) {
+						violated = append(violated, asset)
+					}
+				}
+			case constraint.OrgPolicyConstraintCustom != nil:
+				custom := constraint.OrgPolicyConstraintCustom
+				if !enforced(custom.PolicyRules) {
+					continue
+				}
+				condition, err := ParseCondition(custom.CustomConstraint.Condition)
+				if err != nil {
+					return template.IACValidationReport{}, fmt.Errorf("policy %s: invalid condition: %v", policy.PolicyID, err)
+				}
+				violation.ViolatedPolicy.Constraint = custom.CustomConstraint.Name
+				violation.ViolatedPolicy.ConstraintType = CONSTRAINT_TYPE_ORG_POLICY_CUSTOM
+				if violation.ViolatedPolicy.Description == "" {
+					violation.ViolatedPolicy.Description = custom.CustomConstraint.Description
+				}
+
+				for _, asset := range assets {
+					if !slices.Contains(custom.CustomConstraint.ResourceTypes, asset.AssetType) {
+						continue
+					}
+					holds, err := condition.Eval(asset.Resource.Data)
+					if err != nil {
+						return template.IACValidationReport{}, fmt.Errorf("policy %s: condition on %s: %v", policy.PolicyID, asset.Name, err)
+					}
+					// ALLOW constraints are violated by the resources for
+					// which the condition does not hold, DENY ones by the
+					// resources for which it holds.
+					if holds == (custom.CustomConstraint.ActionType == "DENY") {
+						violated = append(violated, asset)
+					}
+				}
+			default:
+				skipped = append(skipped, fmt.Sprintf("%s (only org policies are evaluated offline)", policy.PolicyID))
+				continue
+			}
+
+			for _, asset := range violated {
+				serialized, err := asset.JSON()
+				if err != nil {
+					return template.IACValidationReport{}, fmt.Errorf("policy %s: %v", policy.PolicyID, err)
+				}
+
+				assetViolation := violation
+				assetViolation.AssetID = asset.Name
+				assetViolation.ViolatedAsset = template.AssetDetails{Asset: serialized, AssetType: asset.AssetType}
+				report.Violations = append(report.Violations, assetViolation)
+			}
+		}
+	}
+
+	report.Note = fmt.Sprintf("Evaluated offline against posture %s.", posture.Name)
+	if len(skipped) > 0 {
+		report.Note += " Not evaluated: " + strings.Join(skipped, ", ") + "."
+	}
+
+	return report, nil
+}
+
+func complianceStandards(standards []ComplianceStandard) []string {
+	var formatted []string
+	for _, standard := range standards {
+		formatted = append(formatted, strings.TrimSpace(standard.Standard+" "+standard.Control))
+	}
+
+	return formatted
+}
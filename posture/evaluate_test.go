@@ -0,0 +1,292 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package posture
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+	"github.com/mikelaramie/IACPlugin2024/tfplan"
+)
+
+const testPlan = `{
+	"format_version": "1.2",
+	"planned_values": {"root_module": {"resources": [
+		{"address": "google_storage_bucket.logs", "mode": "managed", "provider_name": "registry.terraform.io/hashicorp/google", "type": "google_storage_bucket", "name": "logs", "values": {"name": "logs", "location": "US", "uniform_bucket_level_access": false}},
+		{"address": "google_storage_bucket.data", "mode": "managed", "provider_name": "registry.terraform.io/hashicorp/google", "type": "google_storage_bucket", "name": "data", "values": {"name": "data", "location": "EU", "uniform_bucket_level_access": true}},
+		{"address": "google_compute_instance.vm", "mode": "managed", "provider_name": "registry.terraform.io/hashicorp/google", "type": "google_compute_instance", "name": "vm", "values": {
+			"name": "vm", "zone": "europe-west1-b", "machine_type": "n1-standard-1",
+			"network_interface": [{"network": "default", "access_config": [{}]}]
+		}},
+		{"address": "google_project.p1", "mode": "managed", "provider_name": "registry.terraform.io/hashicorp/google", "type": "google_project", "name": "p1", "values": {"project_id": "p1", "auto_create_network": false}}
+	]}}
+}`
+
+const testPosture = `
+name: organizations/123/locations/global/postures/offline
+revisionId: abc
+policySets:
+- policySetId: storage
+  policies:
+  - policyId: uniform_access
+    complianceStandards:
+    - standard: CIS 2.0
+      control: "5.2"
+    constraint:
+      orgPolicyConstraint:
+        cannedConstraintId: storage.uniformBucketLevelAccess
+        policyRules:
+        - enforce: true
+    annotations:
+      severity: HIGH
+  - policyId: locations
+    constraint:
+      orgPolicyConstraint:
+        cannedConstraintId: gcp.resourceLocations
+        policyRules:
+        - values:
+            allowedValues:
+            - in:eu-locations
+- policySetId: compute
+  policies:
+  - policyId: external_ip
+    constraint:
+      orgPolicyConstraint:
+        cannedConstraintId: compute.vmExternalIpAccess
+        policyRules:
+        - denyAll: true
+    annotations:
+      severity: critical
+  - policyId: machine_types
+    constraint:
+      orgPolicyConstraintCustom:
+        customConstraint:
+          name: organizations/123/customConstraints/custom.e2MachineTypes
+          description: Instances use E2 machine types.
+          resourceTypes:
+          - compute.googleapis.com/Instance
+          condition: resource.machineType.startsWith('e2-')
+          actionType: ALLOW
+        policyRules:
+        - enforce: true
+  - policyId: not_enforced
+    constraint:
+      orgPolicyConstraintCustom:
+        customConstraint:
+          name: organizations/123/customConstraints/custom.noVm
+          resourceTypes:
+          - compute.googleapis.com/Instance
+          condition: "true"
+          actionType: DENY
+        policyRules:
+        - enforce: false
+  - policyId: service_account_keys
+    constraint:
+      orgPolicyConstraint:
+        cannedConstraintId: iam.disableServiceAccountKeyCreation
+        policyRules:
+        - enforce: true
+  - policyId: public_bucket
+    constraint:
+      securityHealthAnalyticsModule:
+        moduleName: PUBLIC_BUCKET_ACL
+`
+
+// violationSummary is the part of a violation the tests compare, the assets
+// being covered by the tfplan tests.
+type violationSummary struct {
+	PolicyID       string
+	AssetID        string
+	Severity       string
+	PolicySet      string
+	Constraint     string
+	ConstraintType string
+	Standards      []string
+}
+
+func TestEvaluate(t *testing.T) {
+	plan, err := tfplan.Parse([]byte(testPlan))
+	if err != nil {
+		t.Fatalf("tfplan.Parse: %v", err)
+	}
+	posture, err := Parse([]byte(testPosture))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	report, err := Evaluate(posture, plan.Assets("p1"))
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+
+	var violations []violationSummary
+	for _, violation := range report.Violations {
+		violations = append(violations, violationSummary{
+			PolicyID:       violation.PolicyID,
+			AssetID:        violation.AssetID,
+			Severity:       violation.Severity,
+			PolicySet:      violation.ViolatedPosture.PolicySet,
+			Constraint:     violation.ViolatedPolicy.Constraint,
+			ConstraintType: violation.ViolatedPolicy.ConstraintType,
+			Standards:      violation.ViolatedPolicy.ComplianceStandards,
+		})
+	}
+
+	expected := []violationSummary{
+		{
+			PolicyID: "uniform_access", AssetID: "//storage.googleapis.com/logs", Severity: "HIGH", PolicySet: "storage",
+			Constraint: "storage.uniformBucketLevelAccess", ConstraintType: CONSTRAINT_TYPE_ORG_POLICY, Standards: []string{"CIS 2.0 5.2"},
+		},
+		{
+			PolicyID: "locations", AssetID: "//storage.googleapis.com/logs", Severity: "MEDIUM", PolicySet: "storage",
+			Constraint: "gcp.resourceLocations", ConstraintType: CONSTRAINT_TYPE_ORG_POLICY,
+		},
+		{
+			PolicyID: "external_ip", AssetID: "//compute.googleapis.com/projects/p1/zones/europe-west1-b/instances/vm", Severity: "CRITICAL", PolicySet: "compute",
+			Constraint: "compute.vmExternalIpAccess", ConstraintType: CONSTRAINT_TYPE_ORG_POLICY,
+		},
+		{
+			PolicyID: "machine_types", AssetID: "//compute.googleapis.com/projects/p1/zones/europe-west1-b/instances/vm", Severity: "MEDIUM", PolicySet: "compute",
+			Constraint: "organizations/123/customConstraints/custom.e2MachineTypes", ConstraintType: CONSTRAINT_TYPE_ORG_POLICY_CUSTOM,
+		},
+	}
+	if diff := cmp.Diff(expected, violations); diff != "" {
+		t.Errorf("Expected violations (+got, -want): %v", diff)
+	}
+
+	expectedNote := "Evaluated offline against posture organizations/123/locations/global/postures/offline. " +
+		"Not evaluated: service_account_keys (constraint iam.disableServiceAccountKeyCreation is not supported offline), " +
+		"public_bucket (only org policies are evaluated offline)."
+	if diff := cmp.Diff(expectedNote, report.Note); diff != "" {
+		t.Errorf("Expected note (+got, -want): %v", diff)
+	}
+
+	asset, err := template.ParseAsset(report.Violations[0].ViolatedAsset.Asset)
+	if err != nil {
+		t.Fatalf("ParseAsset: %v", err)
+	}
+	if asset.AssetType != "storage.googleapis.com/Bucket" || asset.Location != "US" {
+		t.Errorf("Expected the violated bucket in the report, got: %+v", asset)
+	}
+}
+
+func TestEvaluate_UnknownSeverity(t *testing.T) {
+	posture := Posture{PolicySets: []PolicySet{{Policies: []Policy{{
+		PolicyID:    "P1",
+		Annotations: map[string]string{"severity": "URGENT"},
+	}}}}}
+
+	if _, err := Evaluate(posture, nil); err == nil {
+		t.Errorf("Expected an error for an unknown severity")
+	}
+}
+
+func TestEvaluate_ConditionFailure(t *testing.T) {
+	plan, err := tfplan.Parse([]byte(testPlan))
+	if err != nil {
+		t.Fatalf("tfplan.Parse: %v", err)
+	}
+	posture, err := Parse([]byte(`
+name: organizations/123/locations/global/postures/offline
+policySets:
+- policySetId: storage
+  policies:
+  - policyId: retention
+    constraint:
+      orgPolicyConstraintCustom:
+        customConstraint:
+          name: organizations/123/customConstraints/custom.retention
+          resourceTypes:
+          - storage.googleapis.com/Bucket
+          condition: resource.retentionPolicy.retentionPeriod > 0
+          actionType: ALLOW
+        policyRules:
+        - enforce: true
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if _, err := Evaluate(posture, plan.Assets("p1")); err == nil {
+		t.Errorf("Expected an error for a condition failing on an asset")
+	}
+}
+
+func TestListAllows(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []PolicyRule
+		value    string
+		expected bool
+	}{
+		{name: "NoRules_Succeeds", value: "US", expected: true},
+		{name: "AllowAll_Succeeds", rules: []PolicyRule{{AllowAll: true}}, value: "US", expected: true},
+		{name: "DenyAll_Succeeds", rules: []PolicyRule{{DenyAll: true}}, value: "US", expected: false},
+		{name: "Allowed_Succeeds", rules: []PolicyRule{{Values: &PolicyValue{AllowedValues: []string{"is:us-east1"}}}}, value: "us-east1", expected: true},
+		{name: "NotAllowed_Succeeds", rules: []PolicyRule{{Values: &PolicyValue{AllowedValues: []string{"us-east1"}}}}, value: "us-west1", expected: false},
+		{name: "Denied_Succeeds", rules: []PolicyRule{{Values: &PolicyValue{DeniedValues: []string{"us-west1"}}}}, value: "us-west1", expected: false},
+		{name: "ValueGroup_Succeeds", rules: []PolicyRule{{Values: &PolicyValue{AllowedValues: []string{"in:us-locations"}}}}, value: "us-central1-a", expected: true},
+		{name: "EUValueGroup_Succeeds", rules: []PolicyRule{{Values: &PolicyValue{AllowedValues: []string{"in:eu-locations"}}}}, value: "europe-west1", expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := listAllows(test.rules, test.value); got != test.expected {
+				t.Errorf("Expected %v, got: %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestParse_Failure(t *testing.T) {
+	tests := []struct {
+		name    string
+		posture string
+	}{
+		{name: "NotAPosture", posture: `violations: []`},
+		{name: "InvalidCondition", posture: `
+policySets:
+- policies:
+  - policyId: P1
+    constraint:
+      orgPolicyConstraintCustom:
+        customConstraint:
+          condition: resource.name ==
+          actionType: ALLOW
+`},
+		{name: "InvalidActionType", posture: `
+policySets:
+- policies:
+  - policyId: P1
+    constraint:
+      orgPolicyConstraintCustom:
+        customConstraint:
+          condition: "true"
+          actionType: AUDIT
+`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Parse([]byte(test.posture)); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package posture evaluates Terraform plans against the org policies of a
+// posture offline, as a stand-in for the SCC IaC validation.
+package posture
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	CONSTRAINT_TYPE_ORG_POLICY        = "ORG_POLICY"
+	CONSTRAINT_TYPE_ORG_POLICY_CUSTOM = "ORG_POLICY_CUSTOM"
+)
+
+// The annotations of a policy enrich its violations: the severity of the
+// offline evaluation, which is also the risk rating of the catalog, and the
+// owner, runbook and comma separated tags of the catalog.
+const (
+	ANNOTATION_SEVERITY    = "severity"
+	ANNOTATION_OWNER       = "owner"
+	ANNOTATION_RUNBOOK_URL = "runbookUrl"
+	ANNOTATION_TAGS        = "tags"
+)
+
+// Posture is the subset of a posture YAML file the offline evaluation reads.
+type Posture struct {
+	Name       string      `yaml:"name"`
+	RevisionID string      `yaml:"revisionId"`
+	PolicySets []PolicySet `yaml:"policySets"`
+}
+
+type PolicySet struct {
+	PolicySetID string   `yaml:"policySetId"`
+	Policies    []Policy `yaml:"policies"`
+}
+
+type Policy struct {
+	PolicyID            string               `yaml:"policyId"`
+	ComplianceStandards []ComplianceStandard `yaml:"complianceStandards"`
+	Constraint          Constraint           `yaml:"constraint"`
+	Description         string               `yaml:"description"`
+	// Annotations enrich the violations of the policy, see ANNOTATION_SEVERITY.
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+type ComplianceStandard struct {
+	Standard string `yaml:"standard"`
+	Control  string `yaml:"control"`
+}
+
+// Constraint is the org policy or Security Health Analytics module of a
+// posture policy. Modules can not be evaluated against a plan.
+type Constraint struct {
+	OrgPolicyConstraint           *OrgPolicyConstraint           `yaml:"orgPolicyConstraint"`
+	OrgPolicyConstraintCustom     *OrgPolicyConstraintCustom     `yaml:"orgPolicyConstraintCustom"`
+	SecurityHealthAnalyticsModule *SecurityHealthAnalyticsModule `yaml:"securityHealthAnalyticsModule"`
+}
+
+// Name returns the name reports give the constraint: the predefined
+// constraint, the custom constraint or the module name.
+func (c Constraint) Name() string {
+	switch {
+	case c.OrgPolicyConstraint != nil:
+		return c.OrgPolicyConstraint.CannedConstraintID
+	case c.OrgPolicyConstraintCustom != nil:
+		return c.OrgPolicyConstraintCustom.CustomConstraint.Name
+	case c.SecurityHealthAnalyticsModule != nil:
+		return c.SecurityHealthAnalyticsModule.ModuleName
+	}
+
+	return ""
+}
+
+// OrgPolicyConstraint enforces a predefined boolean or list constraint.
+type OrgPolicyConstraint struct {
+	CannedConstraintID string       `yaml:"cannedConstraintId"`
+	PolicyRules        []PolicyRule `yaml:"policyRules"`
+}
+
+// OrgPolicyConstraintCustom enforces a custom constraint.
+type OrgPolicyConstraintCustom struct {
+	CustomConstraint CustomConstraint `yaml:"customConstraint"`
+	PolicyRules      []PolicyRule     `yaml:"policyRules"`
+}
+
+// CustomConstraint allows or denies the resources of its types for which
+// the CEL condition holds.
+type CustomConstraint struct {
+	Name          string   `yaml:"name"`
+	DisplayName   string   `yaml:"displayName"`
+	Description   string   `yaml:"description"`
+	ResourceTypes []string `yaml:"resourceTypes"`
+	Condition     string   `yaml:"condition"`
+	ActionType    string   `yaml:"actionType"`
+}
+
+// PolicyRule is a rule of an org policy: enforce for boolean and custom
+// constraints, the values or allowAll and denyAll for list constraints.
+type PolicyRule struct {
+	Enforce  *bool        `yaml:"enforce"`
+	AllowAll bool         `yaml:"allowAll"`
+	DenyAll  bool         `yaml:"denyAll"`
+	Values   *PolicyValue `yaml:"values"`
+}
+
+type PolicyValue struct {
+	AllowedValues []string `yaml:"allowedValues"`
+	DeniedValues  []string `yaml:"deniedValues"`
+}
+
+// SecurityHealthAnalyticsModule enables a predefined detector.
+type SecurityHealthAnalyticsModule struct {
+	ModuleName string `yaml:"moduleName"`
+}
+
+// Load reads a posture YAML file.
+func Load(filePath string) (Posture, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return Posture{}, fmt.Errorf("os.ReadFile(%s): %v", filePath, err)
+	}
+
+	return Parse(data)
+}
+
+// Parse decodes a posture YAML file and checks the conditions of its custom
+// constraints.
+func Parse(data []byte) (Posture, error) {
+	posture, err := decode(data)
+	if err != nil {
+		return Posture{}, err
+	}
+
+	for _, policySet := range posture.PolicySets {
+		for _, policy := range policySet.Policies {
+			custom := policy.Constraint.OrgPolicyConstraintCustom
+			if custom == nil {
+				continue
+			}
+			if _, err := ParseCondition(custom.CustomConstraint.Condition); err != nil {
+				return Posture{}, fmt.Errorf("policy %s: invalid condition: %v", policy.PolicyID, err)
+			}
+			switch custom.CustomConstraint.ActionType {
+			case "ALLOW", "DENY":
+			default:
+				return Posture{}, fmt.Errorf("policy %s: invalid action type %q, expected ALLOW or DENY", policy.PolicyID, custom.CustomConstraint.ActionType)
+			}
+		}
+	}
+
+	return posture, nil
+}
+
+// decode decodes a posture YAML file without checking its policies.
+func decode(data []byte) (Posture, error) {
+	var posture Posture
+	if err := yaml.Unmarshal(data, &posture); err != nil {
+		return Posture{}, fmt.Errorf("yaml.Unmarshal(): %v", err)
+	}
+	if len(posture.PolicySets) == 0 {
+		return Posture{}, fmt.Errorf("not a posture: policySets is missing")
+	}
+
+	return posture, nil
+}
+
+// enforced reports whether the rules enforce a boolean or custom constraint.
+func enforced(rules []PolicyRule) bool {
+	for _, rule := range rules {
+		if rule.Enforce != nil && *rule.Enforce {
+			return true
+		}
+	}
+
+	return false
+}
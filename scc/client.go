@@ -0,0 +1,175 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package scc submits Terraform plans to the SCC IaC validation API and waits
+// for their report, so pipelines do not need the gcloud SDK.
+package scc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+	"github.com/mikelaramie/IACPlugin2024/tfplan"
+)
+
+const (
+	DEFAULT_ENDPOINT      = "https://securityposture.googleapis.com/v1"
+	DEFAULT_POLL_INTERVAL = 5 * time.Second
+)
+
+// Doer sends HTTP requests. *http.Client implements it, and tests substitute
+// the client of a fake server.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client calls the IaC validation API.
+type Client struct {
+	HTTPClient   Doer
+	Endpoint     string
+	PollInterval time.Duration
+}
+
+// NewClient returns a client of the default endpoint sending its requests
+// with the given HTTP client, which authenticates them.
+func NewClient(httpClient Doer) *Client {
+	return &Client{HTTPClient: httpClient, Endpoint: DEFAULT_ENDPOINT, PollInterval: DEFAULT_POLL_INTERVAL}
+}
+
+// CreateIaCValidationReport submits the Terraform JSON plan for validation
+// under the parent, organizations/<id>/locations/<location>, and polls the
+// long-running operation until it is done, every PollInterval or
+// DEFAULT_POLL_INTERVAL when it is not set. The operation is returned in the
+// format of gcloud scc iac-validation-reports create.
+func (c *Client) CreateIaCValidationReport(ctx context.Context, parent string, plan []byte) (template.Operation, error) {
+	if !strings.HasPrefix(parent, "organizations/") || !strings.Contains(parent, "/locations/") {
+		return template.Operation{}, fmt.Errorf("invalid parent %q, expected organizations/<id>/locations/<location>", parent)
+	}
+
+	body, err := json.Marshal(tfplan.NewValidationRequest(plan))
+	if err != nil {
+		return template.Operation{}, fmt.Errorf("json.Marshal(): %v", err)
+	}
+
+	operation, err := c.call(ctx, http.MethodPost, parent+"/reports:createIaCValidationReport", body)
+	if err != nil {
+		return template.Operation{}, err
+	}
+
+	pollInterval := c.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DEFAULT_POLL_INTERVAL
+	}
+	for !operation.Done {
+		select {
+		case <-ctx.Done():
+			return template.Operation{}, fmt.Errorf("waiting for operation %s: %v", operation.Name, ctx.Err())
+		case <-time.After(pollInterval):
+		}
+
+		operation, err = c.call(ctx, http.MethodGet, operation.Name, nil)
+		if err != nil {
+			return template.Operation{}, err
+		}
+	}
+
+	if operation.Error != nil {
+		return template.Operation{}, fmt.Errorf("operation %s failed: %s (code %d)", operation.Name, operation.Error.Message, operation.Error.Code)
+	}
+
+	return operation, nil
+}
+
+// call sends a request to the resource path and decodes the operation it
+// returns.
+func (c *Client) call(ctx context.Context, method, resource string, body []byte) (template.Operation, error) {
+	url := strings.TrimSuffix(c.Endpoint, "/") + "/" + resource
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return template.Operation{}, fmt.Errorf("http.NewRequest(): %v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return template.Operation{}, fmt.Errorf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return template.Operation{}, fmt.Errorf("%s %s: io.ReadAll(): %v", method, url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return template.Operation{}, fmt.Errorf("%s %s: %s: %s", method, url, resp.Status, errorMessage(data))
+	}
+
+	var operation template.Operation
+	if err := json.Unmarshal(data, &operation); err != nil {
+		return template.Operation{}, fmt.Errorf("%s %s: json.Unmarshal(): %v", method, url, err)
+	}
+	if operation.Name == "" && !operation.Done {
+		return template.Operation{}, fmt.Errorf("%s %s: response is not an operation", method, url)
+	}
+
+	return operation, nil
+}
+
+// errorMessage returns the message of a Google API error response, or the
+// response itself when it is not one.
+func errorMessage(data []byte) string {
+	var response struct {
+		Error template.Status `json:"error"`
+	}
+	if err := json.Unmarshal(data, &response); err == nil && response.Error.Message != "" {
+		return response.Error.Message
+	}
+
+	return strings.TrimSpace(string(data))
+}
+
+// TokenTransport authenticates the requests with an OAuth access token, such
+// as the output of gcloud auth print-access-token or of the auth action of
+// the CI.
+type TokenTransport struct {
+	Token string
+	// Base sends the authenticated requests, http.DefaultTransport when nil.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t TokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	// RoundTrip must not modify the request.
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.Token)
+
+	return base.RoundTrip(req)
+}
@@ -0,0 +1,184 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+	"github.com/mikelaramie/IACPlugin2024/tfplan"
+)
+
+const (
+	testParent = "organizations/123/locations/global"
+	testPlan   = `{"format_version": "1.2"}`
+)
+
+var testReport = template.Responses{
+	Name: testParent + "/reports/r1",
+	IacValidationReport: template.IACValidationReport{Violations: []template.Violation{
+		{AssetID: "//storage.googleapis.com/logs", PolicyID: "P1", Severity: "HIGH"},
+	}},
+}
+
+// fakeServer serves the create request and then the given operations, one
+// per poll, checking the requests of the client.
+func fakeServer(t *testing.T, operations ...interface{}) *httptest.Server {
+	t.Helper()
+
+	polls := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Expected the access token, got Authorization: %q", got)
+		}
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/"+testParent+"/reports:createIaCValidationReport":
+			var request tfplan.ValidationRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Errorf("Decode: %v", err)
+			}
+			if plan, _ := base64.StdEncoding.DecodeString(request.IaC.TfPlan); string(plan) != testPlan {
+				t.Errorf("Expected the plan in the request, got: %q", plan)
+			}
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/"+testParent+"/operations/op1":
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if polls >= len(operations) {
+			t.Errorf("Unexpected request %s %s after the operation is done", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		operation := operations[polls]
+		polls++
+
+		if status, ok := operation.(int); ok {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error": {"code": 403, "message": "permission denied", "status": "PERMISSION_DENIED"}}`))
+			return
+		}
+		json.NewEncoder(w).Encode(operation)
+	}))
+}
+
+func TestCreateIaCValidationReport(t *testing.T) {
+	pending := template.Operation{Name: testParent + "/operations/op1"}
+	done := template.Operation{Name: testParent + "/operations/op1", Done: true, Response: testReport}
+	failed := template.Operation{Name: testParent + "/operations/op1", Done: true, Error: &template.Status{Code: 3, Message: "invalid plan"}}
+
+	tests := []struct {
+		name              string
+		parent            string
+		operations        []interface{}
+		expectedOperation template.Operation
+		wantErr           bool
+	}{
+		{
+			name:              "DoneAtOnce_Succeeds",
+			parent:            testParent,
+			operations:        []interface{}{done},
+			expectedOperation: done,
+		},
+		{
+			name:              "Polled_Succeeds",
+			parent:            testParent,
+			operations:        []interface{}{pending, pending, done},
+			expectedOperation: done,
+		},
+		{
+			name:       "OperationError_Failure",
+			parent:     testParent,
+			operations: []interface{}{pending, failed},
+			wantErr:    true,
+		},
+		{
+			name:       "HTTPError_Failure",
+			parent:     testParent,
+			operations: []interface{}{http.StatusForbidden},
+			wantErr:    true,
+		},
+		{
+			name:    "InvalidParent_Failure",
+			parent:  "projects/p1",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := fakeServer(t, test.operations...)
+			defer server.Close()
+
+			client := NewClient(&http.Client{Transport: TokenTransport{Token: "token"}})
+			client.Endpoint = server.URL + "/v1"
+			client.PollInterval = time.Millisecond
+
+			operation, err := client.CreateIaCValidationReport(context.Background(), test.parent, []byte(testPlan))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if diff := cmp.Diff(test.expectedOperation, operation); diff != "" {
+				t.Errorf("Expected operation (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestCreateIaCValidationReport_Cancelled(t *testing.T) {
+	server := fakeServer(t, template.Operation{Name: testParent + "/operations/op1"})
+	defer server.Close()
+
+	client := NewClient(&http.Client{Transport: TokenTransport{Token: "token"}})
+	client.Endpoint = server.URL + "/v1"
+	client.PollInterval = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.CreateIaCValidationReport(ctx, testParent, []byte(testPlan)); err == nil {
+		t.Errorf("Expected an error once the context is done")
+	}
+}
+
+func TestCreateIaCValidationReport_DefaultPollInterval(t *testing.T) {
+	// A single pending operation is served: polling without waiting would
+	// send more requests than the server expects.
+	server := fakeServer(t, template.Operation{Name: testParent + "/operations/op1"})
+	defer server.Close()
+
+	client := &Client{HTTPClient: &http.Client{Transport: TokenTransport{Token: "token"}}, Endpoint: server.URL + "/v1"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.CreateIaCValidationReport(ctx, testParent, []byte(testPlan)); err == nil {
+		t.Errorf("Expected an error once the context is done")
+	}
+}
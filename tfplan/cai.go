@@ -0,0 +1,165 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package tfplan
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Asset is a planned resource in the Cloud Asset Inventory format the IaC
+// validation service evaluates policies against.
+type Asset struct {
+	Name      string        `json:"name"`
+	AssetType string        `json:"assetType"`
+	Ancestors []string      `json:"ancestors,omitempty"`
+	Resource  AssetResource `json:"resource"`
+	// Address is the Terraform address of the resource.
+	Address string `json:"-"`
+}
+
+// AssetResource is the resource payload of an asset, its data being the
+// planned values of the Terraform resource.
+type AssetResource struct {
+	Version       string                 `json:"version"`
+	DiscoveryName string                 `json:"discoveryName"`
+	Parent        string                 `json:"parent,omitempty"`
+	Location      string                 `json:"location,omitempty"`
+	Data          map[string]interface{} `json:"data,omitempty"`
+}
+
+// assetNames holds the full resource name templates of the asset types,
+// the placeholders being planned values of the resource.
+var assetNames = map[string]string{
+	"google_bigquery_dataset":             "//bigquery.googleapis.com/projects/{project}/datasets/{dataset_id}",
+	"google_bigquery_table":               "//bigquery.googleapis.com/projects/{project}/datasets/{dataset_id}/tables/{table_id}",
+	"google_cloud_run_service":            "//run.googleapis.com/projects/{project}/locations/{location}/services/{name}",
+	"google_cloud_run_v2_service":         "//run.googleapis.com/projects/{project}/locations/{location}/services/{name}",
+	"google_cloudfunctions_function":      "//cloudfunctions.googleapis.com/projects/{project}/locations/{region}/functions/{name}",
+	"google_compute_address":              "//compute.googleapis.com/projects/{project}/regions/{region}/addresses/{name}",
+	"google_compute_disk":                 "//compute.googleapis.com/projects/{project}/zones/{zone}/disks/{name}",
+	"google_compute_firewall":             "//compute.googleapis.com/projects/{project}/global/firewalls/{name}",
+	"google_compute_instance":             "//compute.googleapis.com/projects/{project}/zones/{zone}/instances/{name}",
+	"google_compute_network":              "//compute.googleapis.com/projects/{project}/global/networks/{name}",
+	"google_compute_router":               "//compute.googleapis.com/projects/{project}/regions/{region}/routers/{name}",
+	"google_compute_subnetwork":           "//compute.googleapis.com/projects/{project}/regions/{region}/subnetworks/{name}",
+	"google_container_cluster":            "//container.googleapis.com/projects/{project}/locations/{location}/clusters/{name}",
+	"google_container_node_pool":          "//container.googleapis.com/projects/{project}/locations/{location}/clusters/{cluster}/nodePools/{name}",
+	"google_kms_crypto_key":               "//cloudkms.googleapis.com/{key_ring}/cryptoKeys/{name}",
+	"google_kms_key_ring":                 "//cloudkms.googleapis.com/projects/{project}/locations/{location}/keyRings/{name}",
+	"google_project":                      "//cloudresourcemanager.googleapis.com/projects/{project_id}",
+	"google_pubsub_subscription":          "//pubsub.googleapis.com/projects/{project}/subscriptions/{name}",
+	"google_pubsub_topic":                 "//pubsub.googleapis.com/projects/{project}/topics/{name}",
+	"google_secret_manager_secret":        "//secretmanager.googleapis.com/projects/{project}/secrets/{secret_id}",
+	"google_service_account":              "//iam.googleapis.com/projects/{project}/serviceAccounts/{account_id}@{project}.iam.gserviceaccount.com",
+	"google_sql_database_instance":        "//cloudsql.googleapis.com/projects/{project}/instances/{name}",
+	"google_storage_bucket":               "//storage.googleapis.com/{name}",
+	"google_artifact_registry_repository": "//artifactregistry.googleapis.com/projects/{project}/locations/{location}/repositories/{repository_id}",
+}
+
+// Assets converts the planned Google resources to assets. Resources without
+// a project take the default project, and values unknown until apply are
+// replaced by "_" in the asset names.
+func (p Plan) Assets(defaultProject string) []Asset {
+	assets := []Asset{}
+	for _, r := range p.GoogleResources() {
+		assets = append(assets, newAsset(r, defaultProject))
+	}
+
+	return assets
+}
+
+func newAsset(r Resource, defaultProject string) Asset {
+	values := make(map[string]interface{}, len(r.Values)+1)
+	for key, value := range r.Values {
+		values[key] = value
+	}
+	if project, _ := values["project"].(string); project == "" && defaultProject != "" {
+		values["project"] = defaultProject
+	}
+
+	assetType := AssetType(r.Type)
+	asset := Asset{
+		Name:      assetName(r, values),
+		AssetType: assetType,
+		Address:   r.Address,
+		Resource: AssetResource{
+			Version:       "v1",
+			DiscoveryName: path.Base(assetType),
+			Location:      location(values),
+			Data:          values,
+		},
+	}
+
+	if project, _ := values["project"].(string); project != "" {
+		asset.Resource.Parent = "//cloudresourcemanager.googleapis.com/projects/" + project
+		asset.Ancestors = []string{"projects/" + project}
+	}
+
+	return asset
+}
+
+// assetName expands the name template of the resource type, resources of an
+// unknown type being named after their Terraform address.
+func assetName(r Resource, values map[string]interface{}) string {
+	pattern, ok := assetNames[r.Type]
+	if !ok {
+		return "//terraform/" + r.Address
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.Index(pattern, "{")
+		end := strings.Index(pattern, "}")
+		if start < 0 || end < start {
+			b.WriteString(pattern)
+			break
+		}
+		b.WriteString(pattern[:start])
+
+		value, _ := values[pattern[start+1:end]].(string)
+		if value == "" {
+			value = "_"
+		}
+		b.WriteString(value)
+		pattern = pattern[end+1:]
+	}
+
+	return b.String()
+}
+
+func location(values map[string]interface{}) string {
+	for _, field := range []string{"location", "region", "zone"} {
+		if value, _ := values[field].(string); value != "" {
+			return path.Base(value)
+		}
+	}
+
+	return ""
+}
+
+// JSON serializes the asset the way violations embed it.
+func (a Asset) JSON() (string, error) {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return "", fmt.Errorf("json.Marshal(): %v", err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,105 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package tfplan
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPlanAssets(t *testing.T) {
+	plan, err := Parse([]byte(testPlan))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	expected := []Asset{
+		{
+			Name:      "//storage.googleapis.com/logs",
+			AssetType: "storage.googleapis.com/Bucket",
+			Ancestors: []string{"projects/default-project"},
+			Address:   "google_storage_bucket.logs",
+			Resource: AssetResource{
+				Version:       "v1",
+				DiscoveryName: "Bucket",
+				Parent:        "//cloudresourcemanager.googleapis.com/projects/default-project",
+				Location:      "EU",
+				Data:          map[string]interface{}{"name": "logs", "location": "EU", "project": "default-project"},
+			},
+		},
+		{
+			Name:      "//compute.googleapis.com/projects/default-project/global/networks/_",
+			AssetType: "compute.googleapis.com/Network",
+			Ancestors: []string{"projects/default-project"},
+			Address:   "module.network.google_compute_network.vpc",
+			Resource: AssetResource{
+				Version:       "v1",
+				DiscoveryName: "Network",
+				Parent:        "//cloudresourcemanager.googleapis.com/projects/default-project",
+				Data:          map[string]interface{}{"project": "default-project"},
+			},
+		},
+		{
+			Name:      "//terraform/module.network.google_compute_router_nat.nat",
+			AssetType: "google_compute_router_nat",
+			Ancestors: []string{"projects/default-project"},
+			Address:   "module.network.google_compute_router_nat.nat",
+			Resource: AssetResource{
+				Version:       "v1",
+				DiscoveryName: "google_compute_router_nat",
+				Parent:        "//cloudresourcemanager.googleapis.com/projects/default-project",
+				Data:          map[string]interface{}{"project": "default-project"},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, plan.Assets("default-project")); diff != "" {
+		t.Errorf("Expected assets (+got, -want): %v", diff)
+	}
+}
+
+func TestAssetName(t *testing.T) {
+	tests := []struct {
+		name         string
+		resource     Resource
+		expectedName string
+	}{
+		{
+			name:         "Instance_Succeeds",
+			resource:     Resource{Type: "google_compute_instance", Values: map[string]interface{}{"project": "p1", "zone": "europe-west1-b", "name": "vm"}},
+			expectedName: "//compute.googleapis.com/projects/p1/zones/europe-west1-b/instances/vm",
+		},
+		{
+			name:         "ServiceAccount_RepeatsProject",
+			resource:     Resource{Type: "google_service_account", Values: map[string]interface{}{"project": "p1", "account_id": "ci"}},
+			expectedName: "//iam.googleapis.com/projects/p1/serviceAccounts/ci@p1.iam.gserviceaccount.com",
+		},
+		{
+			name:         "UnknownValue_Placeholder",
+			resource:     Resource{Type: "google_pubsub_topic", Values: map[string]interface{}{"name": "events"}},
+			expectedName: "//pubsub.googleapis.com/projects/_/topics/events",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.expectedName, assetName(test.resource, test.resource.Values)); diff != "" {
+				t.Errorf("Expected name (+got, -want): %v", diff)
+			}
+		})
+	}
+}
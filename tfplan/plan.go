@@ -0,0 +1,173 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package tfplan reads the JSON representation of Terraform plans printed by
+// terraform show -json.
+package tfplan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Plan is the subset of a Terraform JSON plan the tools read.
+type Plan struct {
+	FormatVersion    string           `json:"format_version"`
+	TerraformVersion string           `json:"terraform_version,omitempty"`
+	PlannedValues    PlannedValues    `json:"planned_values"`
+	ResourceChanges  []ResourceChange `json:"resource_changes,omitempty"`
+}
+
+type PlannedValues struct {
+	RootModule Module `json:"root_module"`
+}
+
+// Module holds the planned resources of a module and its child modules.
+type Module struct {
+	Address      string     `json:"address,omitempty"`
+	Resources    []Resource `json:"resources,omitempty"`
+	ChildModules []Module   `json:"child_modules,omitempty"`
+}
+
+// Resource is a resource as planned, its values being the attributes of the
+// provider schema.
+type Resource struct {
+	Address      string                 `json:"address"`
+	Mode         string                 `json:"mode"`
+	Type         string                 `json:"type"`
+	Name         string                 `json:"name"`
+	ProviderName string                 `json:"provider_name"`
+	Values       map[string]interface{} `json:"values,omitempty"`
+}
+
+type ResourceChange struct {
+	Address       string `json:"address"`
+	ModuleAddress string `json:"module_address,omitempty"`
+	Mode          string `json:"mode"`
+	Type          string `json:"type"`
+	Name          string `json:"name"`
+	ProviderName  string `json:"provider_name"`
+	Change        Change `json:"change"`
+}
+
+type Change struct {
+	Actions []string               `json:"actions"`
+	Before  map[string]interface{} `json:"before,omitempty"`
+	After   map[string]interface{} `json:"after,omitempty"`
+}
+
+// AssetTypes maps the Terraform resource types of the Google provider to
+// their Cloud Asset Inventory asset types.
+var AssetTypes = map[string]string{
+	"google_bigquery_dataset":             "bigquery.googleapis.com/Dataset",
+	"google_bigquery_table":               "bigquery.googleapis.com/Table",
+	"google_cloud_run_service":            "run.googleapis.com/Service",
+	"google_cloud_run_v2_service":         "run.googleapis.com/Service",
+	"google_cloudfunctions_function":      "cloudfunctions.googleapis.com/CloudFunction",
+	"google_compute_address":              "compute.googleapis.com/Address",
+	"google_compute_disk":                 "compute.googleapis.com/Disk",
+	"google_compute_firewall":             "compute.googleapis.com/Firewall",
+	"google_compute_instance":             "compute.googleapis.com/Instance",
+	"google_compute_network":              "compute.googleapis.com/Network",
+	"google_compute_router":               "compute.googleapis.com/Router",
+	"google_compute_subnetwork":           "compute.googleapis.com/Subnetwork",
+	"google_container_cluster":            "container.googleapis.com/Cluster",
+	"google_container_node_pool":          "container.googleapis.com/NodePool",
+	"google_kms_crypto_key":               "cloudkms.googleapis.com/CryptoKey",
+	"google_kms_key_ring":                 "cloudkms.googleapis.com/KeyRing",
+	"google_project":                      "cloudresourcemanager.googleapis.com/Project",
+	"google_pubsub_subscription":          "pubsub.googleapis.com/Subscription",
+	"google_pubsub_topic":                 "pubsub.googleapis.com/Topic",
+	"google_secret_manager_secret":        "secretmanager.googleapis.com/Secret",
+	"google_service_account":              "iam.googleapis.com/ServiceAccount",
+	"google_sql_database_instance":        "sqladmin.googleapis.com/Instance",
+	"google_storage_bucket":               "storage.googleapis.com/Bucket",
+	"google_artifact_registry_repository": "artifactregistry.googleapis.com/Repository",
+}
+
+// Load reads a Terraform JSON plan.
+func Load(filePath string) (Plan, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return Plan{}, fmt.Errorf("os.ReadFile(%s): %v", filePath, err)
+	}
+
+	return Parse(data)
+}
+
+// Parse decodes a Terraform JSON plan.
+func Parse(data []byte) (Plan, error) {
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return Plan{}, fmt.Errorf("json.Unmarshal(): %v", err)
+	}
+	if plan.FormatVersion == "" {
+		return Plan{}, fmt.Errorf("not a terraform json plan: format_version is missing")
+	}
+
+	return plan, nil
+}
+
+// GoogleResources returns the managed resources of the Google provider
+// planned in every module, i.e. the assets a validation report covers.
+func (p Plan) GoogleResources() []Resource {
+	resources := []Resource{}
+
+	var walk func(Module)
+	walk = func(m Module) {
+		for _, r := range m.Resources {
+			if r.Mode == "managed" && IsGoogleProvider(r.ProviderName) {
+				resources = append(resources, r)
+			}
+		}
+		for _, child := range m.ChildModules {
+			walk(child)
+		}
+	}
+	walk(p.PlannedValues.RootModule)
+
+	return resources
+}
+
+// AssetCounts counts the planned Google resources per asset type, keyed by
+// the Terraform resource type when it has no known asset type.
+func (p Plan) AssetCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, r := range p.GoogleResources() {
+		counts[AssetType(r.Type)]++
+	}
+
+	return counts
+}
+
+// AssetType returns the asset type of a Terraform resource type, or the
+// resource type itself when it is not known.
+func AssetType(resourceType string) string {
+	if assetType, ok := AssetTypes[resourceType]; ok {
+		return assetType
+	}
+
+	return resourceType
+}
+
+// IsGoogleProvider reports whether the provider is the Google or Google Beta
+// provider.
+func IsGoogleProvider(providerName string) bool {
+	return strings.HasSuffix(providerName, "/google") || strings.HasSuffix(providerName, "/google-beta") ||
+		providerName == "google" || providerName == "google-beta"
+}
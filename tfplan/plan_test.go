@@ -0,0 +1,61 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package tfplan
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testPlan = `{
+	"format_version": "1.2",
+	"terraform_version": "1.7.0",
+	"planned_values": {"root_module": {
+		"resources": [
+			{"address": "google_storage_bucket.logs", "mode": "managed", "type": "google_storage_bucket", "name": "logs", "provider_name": "registry.terraform.io/hashicorp/google", "values": {"name": "logs", "location": "EU"}},
+			{"address": "data.google_project.current", "mode": "data", "type": "google_project", "name": "current", "provider_name": "registry.terraform.io/hashicorp/google"},
+			{"address": "random_id.suffix", "mode": "managed", "type": "random_id", "name": "suffix", "provider_name": "registry.terraform.io/hashicorp/random"}
+		],
+		"child_modules": [{"address": "module.network", "resources": [
+			{"address": "module.network.google_compute_network.vpc", "mode": "managed", "type": "google_compute_network", "name": "vpc", "provider_name": "registry.terraform.io/hashicorp/google-beta"},
+			{"address": "module.network.google_compute_router_nat.nat", "mode": "managed", "type": "google_compute_router_nat", "name": "nat", "provider_name": "registry.terraform.io/hashicorp/google"}
+		]}]
+	}}
+}`
+
+func TestPlanAssetCounts(t *testing.T) {
+	plan, err := Parse([]byte(testPlan))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	expected := map[string]int{
+		"storage.googleapis.com/Bucket":  1,
+		"compute.googleapis.com/Network": 1,
+		"google_compute_router_nat":      1,
+	}
+	if diff := cmp.Diff(expected, plan.AssetCounts()); diff != "" {
+		t.Errorf("Expected asset counts (+got, -want): %v", diff)
+	}
+}
+
+func TestParse_NotAPlan(t *testing.T) {
+	if _, err := Parse([]byte(`{"violations": []}`)); err == nil {
+		t.Errorf("Expected an error for a document without format_version")
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package tfplan
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+// ValidationRequest is the body of the reports:createIaCValidationReport call
+// gcloud scc iac-validation-reports create makes with the plan.
+type ValidationRequest struct {
+	IaC IaC `json:"iac"`
+}
+
+type IaC struct {
+	// TfPlan is the base64 encoded Terraform JSON plan.
+	TfPlan string `json:"tfPlan"`
+}
+
+// NewValidationRequest wraps the Terraform JSON plan in a validation request.
+func NewValidationRequest(plan []byte) ValidationRequest {
+	return ValidationRequest{IaC: IaC{TfPlan: base64.StdEncoding.EncodeToString(plan)}}
+}
+
+// ResolveReport completes a mocked report with the planned assets, so it reads
+// like a report returned by SCC. The assetId of each violation is either the
+// Terraform address or the asset name of a planned resource, and is replaced
+// by the asset name; the violated asset and its type are filled in unless the
+// mock sets them.
+func ResolveReport(report template.IACValidationReport, assets []Asset) (template.IACValidationReport, error) {
+	byID := make(map[string]Asset, 2*len(assets))
+	for _, asset := range assets {
+		byID[asset.Address] = asset
+		byID[asset.Name] = asset
+	}
+
+	resolved := template.IACValidationReport{Note: report.Note}
+	for i, violation := range report.Violations {
+		asset, ok := byID[violation.AssetID]
+		if !ok {
+			return template.IACValidationReport{}, fmt.Errorf("violation %d: asset %q is not planned", i, violation.AssetID)
+		}
+
+		violation.AssetID = asset.Name
+		if violation.ViolatedAsset.Asset == "" {
+			serialized, err := asset.JSON()
+			if err != nil {
+				return template.IACValidationReport{}, fmt.Errorf("violation %d: %v", i, err)
+			}
+			violation.ViolatedAsset.Asset = serialized
+		}
+		if violation.ViolatedAsset.AssetType == "" {
+			violation.ViolatedAsset.AssetType = asset.AssetType
+		}
+		resolved.Violations = append(resolved.Violations, violation)
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,118 @@
+/*
+ Copyright 2024 Google LLC
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package tfplan
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/mikelaramie/IACPlugin2024/SARIFConverter/template"
+)
+
+func TestResolveReport(t *testing.T) {
+	plan, err := Parse([]byte(testPlan))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	assets := plan.Assets("p1")
+
+	bucket, err := assets[0].JSON()
+	if err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		report         template.IACValidationReport
+		expectedReport template.IACValidationReport
+		wantErr        bool
+	}{
+		{
+			name: "ByAddressAndName_Succeeds",
+			report: template.IACValidationReport{Note: "mock", Violations: []template.Violation{
+				{AssetID: "google_storage_bucket.logs", PolicyID: "P1", Severity: "HIGH"},
+				{AssetID: "//storage.googleapis.com/logs", PolicyID: "P2", ViolatedAsset: template.AssetDetails{Asset: "{}"}},
+			}},
+			expectedReport: template.IACValidationReport{Note: "mock", Violations: []template.Violation{
+				{
+					AssetID: "//storage.googleapis.com/logs", PolicyID: "P1", Severity: "HIGH",
+					ViolatedAsset: template.AssetDetails{Asset: bucket, AssetType: "storage.googleapis.com/Bucket"},
+				},
+				{
+					AssetID: "//storage.googleapis.com/logs", PolicyID: "P2",
+					ViolatedAsset: template.AssetDetails{Asset: "{}", AssetType: "storage.googleapis.com/Bucket"},
+				},
+			}},
+		},
+		{
+			name: "UnplannedAsset_Failure",
+			report: template.IACValidationReport{Violations: []template.Violation{
+				{AssetID: "google_storage_bucket.missing", PolicyID: "P1"},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report, err := ResolveReport(test.report, assets)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", test.wantErr, err)
+			}
+
+			if diff := cmp.Diff(test.expectedReport, report); diff != "" {
+				t.Errorf("Expected report (+got, -want): %v", diff)
+			}
+		})
+	}
+}
+
+func TestResolveReport_ReadsAsSCCReport(t *testing.T) {
+	plan, err := Parse([]byte(testPlan))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	report, err := ResolveReport(template.IACValidationReport{Violations: []template.Violation{
+		{AssetID: "google_storage_bucket.logs", PolicyID: "P1", Severity: "HIGH"},
+	}}, plan.Assets("p1"))
+	if err != nil {
+		t.Fatalf("ResolveReport: %v", err)
+	}
+
+	asset, err := template.ParseAsset(report.Violations[0].ViolatedAsset.Asset)
+	if err != nil {
+		t.Fatalf("ParseAsset: %v", err)
+	}
+	if asset.Project != "p1" || asset.Location != "EU" {
+		t.Errorf("Expected the resolved asset in project p1 and location EU, got: %+v", asset)
+	}
+}
+
+func TestNewValidationRequest(t *testing.T) {
+	request := NewValidationRequest([]byte(testPlan))
+
+	plan, err := base64.StdEncoding.DecodeString(request.IaC.TfPlan)
+	if err != nil {
+		t.Fatalf("base64.DecodeString: %v", err)
+	}
+	if diff := cmp.Diff(testPlan, string(plan)); diff != "" {
+		t.Errorf("Expected plan (+got, -want): %v", diff)
+	}
+}